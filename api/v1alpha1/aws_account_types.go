@@ -0,0 +1,65 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AWSAccountSpec defines how the operator accesses an AWS account
+type AWSAccountSpec struct {
+	// ARN of the IAM role to assume in the account.
+	// +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`
+	RoleARN string `json:"roleARN"`
+	// External ID required by the trust policy of the role, if any.
+	// +optional
+	ExternalID string `json:"externalID,omitempty"`
+	// Region to manage resources in. Defaults to the region of the operator.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Namespaces whose EIPs, ENIs and ENIPools may use the account. All
+	// namespaces may use it if not given. ClusterEIPs may always use it.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Role ARN",type=string,JSONPath=`.spec.roleARN`
+// +kubebuilder:printcolumn:name="Region",type=string,JSONPath=`.spec.region`
+
+// AWSAccount is an AWS account EIPs, ENIs and ENIPools can be managed in by
+// referencing it in awsAccountName.
+type AWSAccount struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AWSAccountSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AWSAccountList contains a list of AWSAccount
+type AWSAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AWSAccount `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AWSAccount{}, &AWSAccountList{})
+}
@@ -0,0 +1,56 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Public IP",type=string,JSONPath=`.status.publicIPAddress`
+// +kubebuilder:printcolumn:name="Carrier IP",type=string,JSONPath=`.status.carrierIPAddress`,priority=1
+// +kubebuilder:printcolumn:name="Network Border Group",type=string,JSONPath=`.status.networkBorderGroup`,priority=1
+// +kubebuilder:printcolumn:name="Reverse DNS",type=string,JSONPath=`.status.reverseDNS.ptrRecord`,priority=1
+// +kubebuilder:printcolumn:name="Private IP",type=string,JSONPath=`.status.assignment.privateIPAddress`
+// +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.status.assignment.namespace`
+// +kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.status.assignment.podName`
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.status.assignment.nodeName`
+// +kubebuilder:printcolumn:name="ENI",type=string,JSONPath=`.status.assignment.eni`
+
+// ClusterEIP is a cluster-scoped EIP. It is not deleted together with a
+// namespace, and its assignment can target pods and ENIs in any namespace.
+type ClusterEIP struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EIPSpec   `json:"spec,omitempty"`
+	Status EIPStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterEIPList contains a list of ClusterEIP
+type ClusterEIPList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterEIP `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterEIP{}, &ClusterEIPList{})
+}
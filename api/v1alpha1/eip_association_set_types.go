@@ -0,0 +1,83 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EIPAssociationSetSpec defines the desired state of EIPAssociationSet
+type EIPAssociationSetSpec struct {
+	// Pods to assign EIPs to. The EIP is picked by the ordinal of the pod,
+	// i.e. the number at the end of the name of a StatefulSet pod. Pods not
+	// controlled by a StatefulSet are ignored.
+	PodSelector metav1.LabelSelector `json:"podSelector"`
+
+	// Name of the EIP for a pod, with {ordinal} replaced by the ordinal of
+	// the pod, e.g. egress-{ordinal}.
+	// +optional
+	EIPNameTemplate string `json:"eipNameTemplate,omitempty"`
+	// EIPs to assign. The pod with ordinal N gets the EIP labeled with
+	// aws.k8s.logmein.com/ordinal=N.
+	// +optional
+	EIPSelector *metav1.LabelSelector `json:"eipSelector,omitempty"`
+
+	// Priority of the generated EIPAssociations.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+}
+
+type EIPAssociationSetMapping struct {
+	PodName            string `json:"podName"`
+	EIPName            string `json:"eipName"`
+	EIPAssociationName string `json:"eipAssociationName"`
+}
+
+// EIPAssociationSetStatus defines the observed state of EIPAssociationSet
+type EIPAssociationSetStatus struct {
+	// Pods that EIPs are currently associated with.
+	// +optional
+	Mappings []EIPAssociationSetMapping `json:"mappings,omitempty"`
+	// Last error that occurred while mapping pods to EIPs.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="EIP Name Template",type=string,JSONPath=`.spec.eipNameTemplate`
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.error`
+
+// EIPAssociationSet generates an EIPAssociation for each selected pod
+type EIPAssociationSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EIPAssociationSetSpec   `json:"spec,omitempty"`
+	Status EIPAssociationSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EIPAssociationSetList contains a list of EIPAssociationSet
+type EIPAssociationSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EIPAssociationSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EIPAssociationSet{}, &EIPAssociationSetList{})
+}
@@ -22,16 +22,84 @@ import (
 type EIPAssociationSpec struct {
 	Assignment *EIPAssignment `json:"assignment,omitempty"`
 	EIPName    string         `json:"eipName,omitempty"`
+
+	// Reference to an EIP, possibly in another namespace. Referencing an
+	// EIP in another namespace requires an EIPReferenceGrant in that
+	// namespace permitting it.
+	// +optional
+	EIPRef *EIPReference `json:"eipRef,omitempty"`
+
+	// If multiple associations reference the same EIP, the one with the
+	// highest priority gets the EIP when it is free. Associations with the
+	// same priority get it in the order they were created. The association
+	// currently holding the EIP keeps it until it is deleted.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+}
+
+type EIPReference struct {
+	// Name of the EIP. Defaults to eipName.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Namespace of the EIP. Defaults to the namespace of the association.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// EIPAssociationPhase is the phase of an EIPAssociation.
+// +kubebuilder:validation:Enum=Pending;Bound;Conflict;Lost
+type EIPAssociationPhase string
+
+const (
+	// The EIP does not exist, is not allocated yet, is being assigned or is
+	// held by another association with higher precedence.
+	EIPAssociationPhasePending EIPAssociationPhase = "Pending"
+	// The EIP is assigned as given in the association.
+	EIPAssociationPhaseBound EIPAssociationPhase = "Bound"
+	// The EIP is assigned to something else.
+	EIPAssociationPhaseConflict EIPAssociationPhase = "Conflict"
+	// The EIP was bound, but has been assigned to something else or deleted
+	// since. It is bound again as soon as it is free.
+	EIPAssociationPhaseLost EIPAssociationPhase = "Lost"
+)
+
+// EIPAssociationConditionBound is the condition type telling whether the EIP
+// is assigned as given in the association.
+const EIPAssociationConditionBound = "Bound"
+
+// EIPAssociationStatus defines the observed state of EIPAssociation
+type EIPAssociationStatus struct {
+	// +optional
+	Phase EIPAssociationPhase `json:"phase,omitempty"`
+
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// The EIP the association assigned, and the assignment it set. Used to
+	// follow later changes of the association, and to release the EIP when
+	// the association is deleted.
+	// +optional
+	Binding *EIPAssociationBinding `json:"binding,omitempty"`
+}
+
+type EIPAssociationBinding struct {
+	// The EIP, with name and namespace resolved.
+	EIPRef EIPReference `json:"eipRef"`
+	// The assignment as set on the EIP.
+	Assignment *EIPAssignment `json:"assignment"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:printcolumn:name="Pod Name",type=string,JSONPath=`.spec.assignment.podName`
 // +kubebuilder:printcolumn:name="EIP Name",type=string,JSONPath=`.spec.eipName`
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 type EIPAssociation struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec EIPAssociationSpec `json:"spec,omitempty"`
+	Spec   EIPAssociationSpec   `json:"spec,omitempty"`
+	Status EIPAssociationStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,72 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EIPInventorySpec defines the desired state of EIPInventory
+type EIPInventorySpec struct {
+	// EIPs in the namespace of the inventory to list. All EIPs in the
+	// namespace are listed if not given.
+	// +optional
+	EIPSelector *metav1.LabelSelector `json:"eipSelector,omitempty"`
+	// ClusterEIPs to list as well. No ClusterEIPs are listed if not given.
+	// +optional
+	ClusterEIPSelector *metav1.LabelSelector `json:"clusterEIPSelector,omitempty"`
+
+	// Name of the ConfigMap to generate. Defaults to the name of the
+	// inventory.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+}
+
+// EIPInventoryStatus defines the observed state of EIPInventory
+type EIPInventoryStatus struct {
+	// Number of public IP addresses listed.
+	Count int `json:"count"`
+	// Last error that occurred while generating the ConfigMap.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Count",type=integer,JSONPath=`.status.count`
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.error`
+
+// EIPInventory lists the public IP addresses of the selected EIPs in a
+// generated ConfigMap, e.g. for allow-listing them in firewalls.
+type EIPInventory struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EIPInventorySpec   `json:"spec,omitempty"`
+	Status EIPInventoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EIPInventoryList contains a list of EIPInventory
+type EIPInventoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EIPInventory `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EIPInventory{}, &EIPInventoryList{})
+}
@@ -0,0 +1,112 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AmazonPublicIPv4Pool stands for Amazon's pool of IPv4 addresses, i.e. EIPs
+// without publicIPv4Pool, in allowedPublicIPv4Pools.
+const AmazonPublicIPv4Pool = "amazon"
+
+// EIPPolicySpec defines the restrictions for EIPs and ENIs in a namespace.
+// Fields that are not given do not restrict anything.
+type EIPPolicySpec struct {
+	// Maximum number of EIPs in the namespace.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxEIPs *int32 `json:"maxEIPs,omitempty"`
+	// Maximum number of ENIs in the namespace, including ENIs kept available
+	// by ENIPools.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxENIs *int32 `json:"maxENIs,omitempty"`
+
+	// Public IPv4 pools EIPs may be allocated from. Use "amazon" to
+	// allow EIPs from Amazon's pool. EIPs requesting a specific publicIPAddress are
+	// rejected if given. For EIPs allocated from an IPAM pool, the public
+	// IPv4 pool chosen when allocating is checked.
+	// +optional
+	AllowedPublicIPv4Pools []string `json:"allowedPublicIPv4Pools,omitempty"`
+	// Subnets ENIs may be created in.
+	// +optional
+	AllowedSubnetIDs []string `json:"allowedSubnetIDs,omitempty"`
+	// Security groups, by ID or name as given in the ENI, ENIs may use.
+	// +optional
+	AllowedSecurityGroups []string `json:"allowedSecurityGroups,omitempty"`
+
+	// Keys of tags that need to be given in the tags of EIPs, ENIs and
+	// ENIPools.
+	// +optional
+	RequiredTags []string `json:"requiredTags,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EIPPolicy restricts the EIPs and ENIs in its namespace. If multiple
+// policies apply to a namespace, all of them need to be satisfied.
+type EIPPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec EIPPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EIPPolicyList contains a list of EIPPolicy
+type EIPPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EIPPolicy `json:"items"`
+}
+
+// ClusterEIPPolicySpec defines the restrictions for EIPs and ENIs in the
+// selected namespaces.
+type ClusterEIPPolicySpec struct {
+	// Namespaces the policy applies to. It applies to all namespaces if not
+	// given.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	EIPPolicySpec `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// ClusterEIPPolicy restricts the EIPs and ENIs in each of the selected
+// namespaces. Quotas apply to each namespace separately.
+type ClusterEIPPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterEIPPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterEIPPolicyList contains a list of ClusterEIPPolicy
+type ClusterEIPPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterEIPPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EIPPolicy{}, &EIPPolicyList{}, &ClusterEIPPolicy{}, &ClusterEIPPolicyList{})
+}
@@ -0,0 +1,61 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type EIPReferenceGrantFrom struct {
+	// Namespace whose EIPAssociations may reference EIPs.
+	Namespace string `json:"namespace"`
+}
+
+// EIPReferenceGrantSpec defines the desired state of EIPReferenceGrant
+type EIPReferenceGrantSpec struct {
+	// Namespaces permitted to reference EIPs in the namespace of the grant.
+	// +kubebuilder:validation:MinItems=1
+	From []EIPReferenceGrantFrom `json:"from"`
+
+	// Names of the EIPs that may be referenced. All EIPs in the namespace
+	// of the grant may be referenced if not given.
+	// +optional
+	EIPNames []string `json:"eipNames,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EIPReferenceGrant permits EIPAssociations in other namespaces to reference
+// EIPs in its namespace.
+type EIPReferenceGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec EIPReferenceGrantSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EIPReferenceGrantList contains a list of EIPReferenceGrant
+type EIPReferenceGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EIPReferenceGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EIPReferenceGrant{}, &EIPReferenceGrantList{})
+}
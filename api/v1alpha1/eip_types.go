@@ -27,11 +27,64 @@ type EIPAssignment struct {
 	ENI                      string `json:"eni,omitempty"`
 	ENIPrivateIPAddressIndex int    `json:"eniPrivateIPAddressIndex,omitempty"`
 
+	// Name of a node to assign the EIP to. It is assigned to the primary
+	// private IP address of the node.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+
+	// Namespace of the pod or ENI. Defaults to the namespace of the EIP and
+	// needs to be given for ClusterEIPs.
+	// Ignored in EIPAssociations, which always assign EIPs to pods and ENIs
+	// in their own namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
 	//ElasticNetworkInterface EIPElasticNetworkInterfaceAssignment `json:"elasticNetworkInterface,omitempty"`
 	//NetworkLoadBalancer     EIPNetworkLoadBalancerAssignment `json:"networkLoadBalancer,omitempty"`
 }
 
+// PoolAllocationStrategy defines how the public IPv4 pool to allocate an EIP
+// from is chosen if multiple pools are given.
+// +kubebuilder:validation:Enum=MostFree;RoundRobin;OrderedFallback;Random
+type PoolAllocationStrategy string
+
+const (
+	// PoolAllocationStrategyMostFree chooses the pool with the most available
+	// addresses.
+	PoolAllocationStrategyMostFree PoolAllocationStrategy = "MostFree"
+	// PoolAllocationStrategyRoundRobin chooses the pools in turn, skipping
+	// exhausted ones.
+	PoolAllocationStrategyRoundRobin PoolAllocationStrategy = "RoundRobin"
+	// PoolAllocationStrategyOrderedFallback chooses the first pool with
+	// available addresses, in the order they are given.
+	PoolAllocationStrategyOrderedFallback PoolAllocationStrategy = "OrderedFallback"
+	// PoolAllocationStrategyRandom chooses a random pool with available
+	// addresses.
+	PoolAllocationStrategyRandom PoolAllocationStrategy = "Random"
+)
+
+// ExternalReleasePolicy defines what happens to an EIP whose allocation was
+// released outside of the operator.
+// +kubebuilder:validation:Enum=Reallocate;MarkLost;Delete
+type ExternalReleasePolicy string
+
+const (
+	// ExternalReleaseReallocate allocates a new EIP, trying to recover the
+	// same public IP address first.
+	ExternalReleaseReallocate ExternalReleasePolicy = "Reallocate"
+	// ExternalReleaseMarkLost moves the EIP to state lost.
+	ExternalReleaseMarkLost ExternalReleasePolicy = "MarkLost"
+	// ExternalReleaseDelete deletes the EIP object.
+	ExternalReleaseDelete ExternalReleasePolicy = "Delete"
+)
+
+// NetworkBorderGroupAuto detects the network border group of an EIP from its
+// assignment.
+const NetworkBorderGroupAuto = "auto"
+
 // EIPSpec defines the desired state of EIP
+// +kubebuilder:validation:XValidation:rule="has(self.awsAccountName) == has(oldSelf.awsAccountName) && (!has(self.awsAccountName) || self.awsAccountName == oldSelf.awsAccountName)",message="awsAccountName is immutable"
+// +kubebuilder:validation:XValidation:rule="has(self.region) == has(oldSelf.region) && (!has(self.region) || self.region == oldSelf.region)",message="region is immutable"
 type EIPSpec struct {
 	// Which resource this EIP should be assigned to.
 	//
@@ -40,15 +93,88 @@ type EIPSpec struct {
 	// +optional
 	Assignment *EIPAssignment `json:"assignment,omitempty"`
 
+	// Name of the AWSAccount to manage the EIP in. Defaults to the account
+	// of the operator.
+	// +optional
+	AWSAccountName string `json:"awsAccountName,omitempty"`
+	// Region to manage the EIP in. Defaults to the region of the AWS account,
+	// or of the operator.
+	// +optional
+	Region string `json:"region,omitempty"`
+
 	PublicIPv4Pool  string   `json:"publicIPv4Pool,omitempty"`
 	PublicIPv4Pools []string `json:"publicIPv4Pools,omitempty"`
 	PublicIPAddress string   `json:"publicIPAddress,omitempty"`
 
+	// ID of an Amazon VPC IPAM pool to allocate the EIP from. The EIP is
+	// allocated from one of the public IPv4 pools in the region the IPAM
+	// pool has provisioned CIDRs to. Ignored if publicIPv4Pool or
+	// publicIPv4Pools is given.
+	// +optional
+	IPAMPoolID string `json:"ipamPoolId,omitempty"`
+	// How to choose among the pools of publicIPv4Pools or ipamPoolId.
+	// Defaults to MostFree.
+	// +optional
+	AllocationStrategy PoolAllocationStrategy `json:"allocationStrategy,omitempty"`
+
+	// Network border group to allocate the EIP from, e.g. of a Local Zone or
+	// Wavelength Zone. EIPs from the network border group of a Wavelength
+	// Zone are carrier IPs. If "auto", the network border group of the zone
+	// the assignment target is in is used, which requires an assignment. It
+	// defaults to the network border group of the region.
+	// +optional
+	NetworkBorderGroup string `json:"networkBorderGroup,omitempty"`
+
+	// Domain name to set as reverse DNS (PTR) record of the EIP. A forward
+	// (A) record of the domain name needs to resolve to the EIP's public IP
+	// address first. The record is reset to Amazon's default if removed.
+	// +optional
+	ReverseDNS string `json:"reverseDNS,omitempty"`
+
+	// Route 53 A record to point at the EIP once it is assigned.
+	// +optional
+	DNS *EIPDNSRecord `json:"dns,omitempty"`
+
+	// What to do if the allocation of the EIP was released outside of the
+	// operator. Defaults to MarkLost.
+	// +optional
+	OnExternalRelease ExternalReleasePolicy `json:"onExternalRelease,omitempty"`
+
 	// Tags that will be applied to the created EIP.
 	// +optional
 	Tags *map[string]string `json:"tags,omitempty"`
 }
 
+// EIPDNSRecord defines a Route 53 A record pointing at an EIP.
+type EIPDNSRecord struct {
+	// ID of the hosted zone to create the record in.
+	HostedZoneID string `json:"hostedZoneId"`
+	// Name of the record, e.g. www.example.com.
+	Name string `json:"name"`
+	// TTL of the record in seconds.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=300
+	// +optional
+	TTL int64 `json:"ttl,omitempty"`
+}
+
+// EIPState is the lifecycle state of an EIP.
+// +kubebuilder:validation:Enum=allocating;allocated;assigning;assigned;reassigning;unassigning;releasing;lost
+type EIPState string
+
+const (
+	EIPStateAllocating  EIPState = "allocating"
+	EIPStateAllocated   EIPState = "allocated"
+	EIPStateAssigning   EIPState = "assigning"
+	EIPStateAssigned    EIPState = "assigned"
+	EIPStateReassigning EIPState = "reassigning"
+	EIPStateUnassigning EIPState = "unassigning"
+	EIPStateReleasing   EIPState = "releasing"
+	// EIPStateLost is the state of EIPs whose allocation was released
+	// outside of the operator.
+	EIPStateLost EIPState = "lost"
+)
+
 // EIPStatus defines the observed state of EIP
 type EIPStatus struct {
 	// Current state of the EIP object.
@@ -59,21 +185,73 @@ type EIPStatus struct {
 	//                   |                         |              |
 	//  *start*:         V                         |              |
 	// allocating -> allocated <-> assigning -> assigned <-> reassigning
-	//                   |             |
-	//   *end*:          |             |
-	//  releasing <------/-------------/
-	State string `json:"state"`
+	//                   |
+	//   *end*:          |
+	//  releasing <------/
+	//
+	// EIPs whose allocation was released outside of the operator change from
+	// any state but allocating and releasing to lost, and from there to
+	// allocating if they are reallocated.
+	//
+	// +optional
+	State EIPState `json:"state,omitempty"`
+	// Time of the last change of the state.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
 
 	AllocationId    string `json:"allocationId,omitempty"`
 	PublicIPAddress string `json:"publicIPAddress,omitempty"`
+	// Public IPv4 pool the EIP was allocated from, if any.
+	PublicIPv4Pool string `json:"publicIPv4Pool,omitempty"`
+	// Carrier IP address of EIPs allocated in a Wavelength Zone, which do
+	// not have a public IP address.
+	CarrierIPAddress   string `json:"carrierIPAddress,omitempty"`
+	NetworkBorderGroup string `json:"networkBorderGroup,omitempty"`
 
 	AssociationId string         `json:"associationId,omitempty"`
 	Assignment    *EIPAssignment `json:"assignment,omitempty"`
+	// Whether the pod of the assignment was annotated with the EIP. The pod
+	// is only annotated again when the assignment changes.
+	// +optional
+	PodAnnotated bool `json:"podAnnotated,omitempty"`
+
+	// +optional
+	ReverseDNS *EIPReverseDNSStatus `json:"reverseDNS,omitempty"`
+	// Route 53 A record created for the EIP.
+	// +optional
+	DNS *EIPDNSRecord `json:"dns,omitempty"`
+
+	// Conditions of the EIP. The Synced condition tells whether the allocation
+	// could be reconciled with AWS.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// EIPReverseDNSStatus defines the observed state of the reverse DNS record of
+// an EIP.
+type EIPReverseDNSStatus struct {
+	// Current PTR record of the EIP.
+	PTRRecord string `json:"ptrRecord,omitempty"`
+	// Domain name last requested by the operator.
+	DomainName string `json:"domainName,omitempty"`
+	// Status of an update of the PTR record in progress, e.g. PENDING.
+	UpdateStatus string `json:"updateStatus,omitempty"`
+	// Why the requested domain name could not be set, e.g. because its
+	// forward record does not resolve to the EIP.
+	Error string `json:"error,omitempty"`
+	// When the domain name was last requested; failed requests are retried
+	// after a back-off.
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
 // +kubebuilder:printcolumn:name="Public IP",type=string,JSONPath=`.status.publicIPAddress`
+// +kubebuilder:printcolumn:name="Carrier IP",type=string,JSONPath=`.status.carrierIPAddress`,priority=1
+// +kubebuilder:printcolumn:name="Network Border Group",type=string,JSONPath=`.status.networkBorderGroup`,priority=1
+// +kubebuilder:printcolumn:name="Reverse DNS",type=string,JSONPath=`.status.reverseDNS.ptrRecord`,priority=1
 // +kubebuilder:printcolumn:name="Private IP",type=string,JSONPath=`.status.assignment.privateIPAddress`
 // +kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.status.assignment.podName`
 // +kubebuilder:printcolumn:name="ENI",type=string,JSONPath=`.status.assignment.eni`
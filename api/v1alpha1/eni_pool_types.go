@@ -0,0 +1,84 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ENIPoolSpec defines the desired state of ENIPool
+// +kubebuilder:validation:XValidation:rule="has(self.awsAccountName) == has(oldSelf.awsAccountName) && (!has(self.awsAccountName) || self.awsAccountName == oldSelf.awsAccountName)",message="awsAccountName is immutable"
+// +kubebuilder:validation:XValidation:rule="has(self.region) == has(oldSelf.region) && (!has(self.region) || self.region == oldSelf.region)",message="region is immutable"
+type ENIPoolSpec struct {
+	SubnetID                       string   `json:"subnetID"`
+	SecurityGroups                 []string `json:"securityGroups"`
+	SecondaryPrivateIPAddressCount int64    `json:"secondaryPrivateIPAddressCount,omitempty"`
+
+	Description string `json:"description,omitempty"`
+
+	// Name of the AWSAccount to create the ENIs in. ENIs claiming from the
+	// pool need to use the same account.
+	// +optional
+	AWSAccountName string `json:"awsAccountName,omitempty"`
+	// Region to manage the ENIs in. Defaults to the region of the AWS account,
+	// or of the operator.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Number of unattached ENIs to keep pre-created.
+	// +kubebuilder:validation:Minimum=0
+	Size int `json:"size"`
+
+	// Tags that will be applied to the pre-created ENIs. They are replaced by
+	// the tags of the ENI object claiming them.
+	// +optional
+	Tags *map[string]string `json:"tags,omitempty"`
+}
+
+// ENIPoolStatus defines the observed state of ENIPool
+type ENIPoolStatus struct {
+	// IDs of the pre-created ENIs that can be claimed.
+	// +optional
+	NetworkInterfaceIDs []string `json:"networkInterfaceIDs,omitempty"`
+	Available           int      `json:"available"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Subnet",type=string,JSONPath=`.spec.subnetID`
+// +kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.spec.size`
+// +kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.available`
+
+// ENIPool is the Schema for the enipools API
+type ENIPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ENIPoolSpec   `json:"spec,omitempty"`
+	Status ENIPoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ENIPoolList contains a list of ENIPool
+type ENIPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ENIPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ENIPool{}, &ENIPoolList{})
+}
@@ -22,12 +22,56 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// ENIAttachment describes the instance an ENI should be attached to.
+//
+// Exactly one of podName, nodeName or instanceID needs to be given.
 type ENIAttachment struct {
+	// Attach the ENI to the instance the given pod is running on.
 	// +kubebuilder:validation:MinLength=0
+	// +optional
 	PodName string `json:"podName,omitempty"`
+	// Attach the ENI to the instance backing the given node.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// Attach the ENI to the given EC2 instance.
+	// +optional
+	InstanceID string `json:"instanceID,omitempty"`
+
+	// Device index to attach the ENI at. If not given, the lowest free
+	// device index on the network card is used.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DeviceIndex *int64 `json:"deviceIndex,omitempty"`
+	// Network card to attach the ENI to. Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	NetworkCardIndex *int64 `json:"networkCardIndex,omitempty"`
+
+	// Whether the ENI is deleted when the instance it is attached to is
+	// terminated. If not given, the setting of the attachment is left as is.
+	// +optional
+	DeleteOnTermination *bool `json:"deleteOnTermination,omitempty"`
+}
+
+// ENIMultus describes how an ENI is made available inside the pod it is
+// attached to by using Multus.
+type ENIMultus struct {
+	// CNI plugin that moves the ENI (host-device) or an interface on top of
+	// it (ipvlan) into the network namespace of the pod.
+	// +kubebuilder:validation:Enum=host-device;ipvlan
+	// +kubebuilder:default=host-device
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Name of the ENI's interface on the node. Required for ipvlan, as
+	// ipvlan cannot select its master interface by MAC address.
+	// +optional
+	Master string `json:"master,omitempty"`
 }
 
 // ENISpec defines the desired state of an ElasticNetworkInterface
+// +kubebuilder:validation:XValidation:rule="has(self.awsAccountName) == has(oldSelf.awsAccountName) && (!has(self.awsAccountName) || self.awsAccountName == oldSelf.awsAccountName)",message="awsAccountName is immutable"
+// +kubebuilder:validation:XValidation:rule="has(self.region) == has(oldSelf.region) && (!has(self.region) || self.region == oldSelf.region)",message="region is immutable"
 type ENISpec struct {
 	SubnetID                       string   `json:"subnetID"`
 	SecurityGroups                 []string `json:"securityGroups"`
@@ -36,25 +80,99 @@ type ENISpec struct {
 	// +optional
 	Attachment *ENIAttachment `json:"attachment,omitempty"`
 
+	// Name of the AWSAccount to manage the ENI in. Defaults to the account
+	// of the operator.
+	// +optional
+	AWSAccountName string `json:"awsAccountName,omitempty"`
+	// Region to manage the ENI in. Defaults to the region of the AWS account,
+	// or of the operator.
+	// +optional
+	Region string `json:"region,omitempty"`
+
 	Description string `json:"description,omitempty"`
 
+	// Name of an ENIPool in the same namespace to claim a pre-created ENI
+	// from. The pool needs to be for the same subnet. If the pool is empty,
+	// the ENI is created as usual.
+	// +optional
+	PoolName string `json:"poolName,omitempty"`
+
+	// Whether source/destination checking is enabled for the ENI. Needs to
+	// be disabled for ENIs of NAT or VPN appliances. If not given, the
+	// setting of the ENI is left as is.
+	// +optional
+	SourceDestCheck *bool `json:"sourceDestCheck,omitempty"`
+
 	// Tags that will be applied to the created EIP.
 	// +optional
 	Tags *map[string]string `json:"tags,omitempty"`
+
+	// If given, a Multus NetworkAttachmentDefinition with the same name as
+	// the ENI is maintained. The pod the ENI is attached to needs to request
+	// it in its k8s.v1.cni.cncf.io/networks annotation, as told by the
+	// MultusNetworkRequested condition.
+	// +optional
+	Multus *ENIMultus `json:"multus,omitempty"`
 }
 
+// ENIState is the lifecycle state of an ENI.
+// +kubebuilder:validation:Enum=creating;available;attaching;attached;detaching;deleting
+type ENIState string
+
+const (
+	ENIStateCreating  ENIState = "creating"
+	ENIStateAvailable ENIState = "available"
+	ENIStateAttaching ENIState = "attaching"
+	ENIStateAttached  ENIState = "attached"
+	ENIStateDetaching ENIState = "detaching"
+	ENIStateDeleting  ENIState = "deleting"
+)
+
 // ENIStatus defines the observed state of ENI
 type ENIStatus struct {
+	// Current state of the ENI object.
+	//
+	// State transfer diagram:
+	//
+	//  *start*:
+	//  creating -> available -> attaching -> attached
+	//               ^   ^           |            |
+	//               |   \-----------/            |
+	//               |                            |
+	//               \-------- detaching <--------/
+	//   *end*:      |
+	//  deleting <---/
+	//
+	// +optional
+	State ENIState `json:"state,omitempty"`
+
 	NetworkInterfaceID string `json:"networkInterfaceID"`
 	MacAddress         string `json:"macAddress"`
 
 	// +optional
 	PrivateIPAddresses []string       `json:"privateIPAddresses,omitempty"`
 	Attachment         *ENIAttachment `json:"attachment,omitempty"`
+	// +optional
+	SourceDestCheck *bool `json:"sourceDestCheck,omitempty"`
+
+	// Last error that occurred while attaching the ENI, e.g. because the
+	// instance has no free ENI slots left.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// Conditions of the ENI. The Synced condition tells whether the network
+	// interface could be reconciled with AWS, the MultusNetworkRequested
+	// condition whether the pod it is attached to requests its Multus network.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
 // +kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.status.attachment.podName`
+// +kubebuilder:printcolumn:name="Instance",type=string,JSONPath=`.status.attachment.instanceID`
 // +kubebuilder:printcolumn:name="Private IP addresses",type=string,JSONPath=`.status.privateIPAddresses`
 
 // ENI is the Schema for the enis API
@@ -28,9 +28,226 @@ CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSAccount) DeepCopyInto(out *AWSAccount) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSAccount.
+func (in *AWSAccount) DeepCopy() *AWSAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AWSAccount) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSAccountList) DeepCopyInto(out *AWSAccountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AWSAccount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSAccountList.
+func (in *AWSAccountList) DeepCopy() *AWSAccountList {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSAccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AWSAccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSAccountSpec) DeepCopyInto(out *AWSAccountSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSAccountSpec.
+func (in *AWSAccountSpec) DeepCopy() *AWSAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEIP) DeepCopyInto(out *ClusterEIP) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEIP.
+func (in *ClusterEIP) DeepCopy() *ClusterEIP {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEIP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterEIP) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEIPList) DeepCopyInto(out *ClusterEIPList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterEIP, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEIPList.
+func (in *ClusterEIPList) DeepCopy() *ClusterEIPList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEIPList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterEIPList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEIPPolicy) DeepCopyInto(out *ClusterEIPPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEIPPolicy.
+func (in *ClusterEIPPolicy) DeepCopy() *ClusterEIPPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEIPPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterEIPPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEIPPolicyList) DeepCopyInto(out *ClusterEIPPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterEIPPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEIPPolicyList.
+func (in *ClusterEIPPolicyList) DeepCopy() *ClusterEIPPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEIPPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterEIPPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEIPPolicySpec) DeepCopyInto(out *ClusterEIPPolicySpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.EIPPolicySpec.DeepCopyInto(&out.EIPPolicySpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEIPPolicySpec.
+func (in *ClusterEIPPolicySpec) DeepCopy() *ClusterEIPPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEIPPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EIP) DeepCopyInto(out *EIP) {
 	*out = *in
@@ -40,18 +257,426 @@ func (in *EIP) DeepCopyInto(out *EIP) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIP.
-func (in *EIP) DeepCopy() *EIP {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIP.
+func (in *EIP) DeepCopy() *EIP {
+	if in == nil {
+		return nil
+	}
+	out := new(EIP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EIP) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssignment) DeepCopyInto(out *EIPAssignment) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssignment.
+func (in *EIPAssignment) DeepCopy() *EIPAssignment {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssignment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssociation) DeepCopyInto(out *EIPAssociation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociation.
+func (in *EIPAssociation) DeepCopy() *EIPAssociation {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssociation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EIPAssociation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssociationBinding) DeepCopyInto(out *EIPAssociationBinding) {
+	*out = *in
+	out.EIPRef = in.EIPRef
+	if in.Assignment != nil {
+		in, out := &in.Assignment, &out.Assignment
+		*out = new(EIPAssignment)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationBinding.
+func (in *EIPAssociationBinding) DeepCopy() *EIPAssociationBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssociationBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssociationList) DeepCopyInto(out *EIPAssociationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EIPAssociation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationList.
+func (in *EIPAssociationList) DeepCopy() *EIPAssociationList {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssociationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EIPAssociationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssociationSet) DeepCopyInto(out *EIPAssociationSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationSet.
+func (in *EIPAssociationSet) DeepCopy() *EIPAssociationSet {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssociationSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EIPAssociationSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssociationSetList) DeepCopyInto(out *EIPAssociationSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EIPAssociationSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationSetList.
+func (in *EIPAssociationSetList) DeepCopy() *EIPAssociationSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssociationSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EIPAssociationSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssociationSetMapping) DeepCopyInto(out *EIPAssociationSetMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationSetMapping.
+func (in *EIPAssociationSetMapping) DeepCopy() *EIPAssociationSetMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssociationSetMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssociationSetSpec) DeepCopyInto(out *EIPAssociationSetSpec) {
+	*out = *in
+	in.PodSelector.DeepCopyInto(&out.PodSelector)
+	if in.EIPSelector != nil {
+		in, out := &in.EIPSelector, &out.EIPSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationSetSpec.
+func (in *EIPAssociationSetSpec) DeepCopy() *EIPAssociationSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssociationSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssociationSetStatus) DeepCopyInto(out *EIPAssociationSetStatus) {
+	*out = *in
+	if in.Mappings != nil {
+		in, out := &in.Mappings, &out.Mappings
+		*out = make([]EIPAssociationSetMapping, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationSetStatus.
+func (in *EIPAssociationSetStatus) DeepCopy() *EIPAssociationSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssociationSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssociationSpec) DeepCopyInto(out *EIPAssociationSpec) {
+	*out = *in
+	if in.Assignment != nil {
+		in, out := &in.Assignment, &out.Assignment
+		*out = new(EIPAssignment)
+		**out = **in
+	}
+	if in.EIPRef != nil {
+		in, out := &in.EIPRef, &out.EIPRef
+		*out = new(EIPReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationSpec.
+func (in *EIPAssociationSpec) DeepCopy() *EIPAssociationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssociationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPAssociationStatus) DeepCopyInto(out *EIPAssociationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Binding != nil {
+		in, out := &in.Binding, &out.Binding
+		*out = new(EIPAssociationBinding)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationStatus.
+func (in *EIPAssociationStatus) DeepCopy() *EIPAssociationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPAssociationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPDNSRecord) DeepCopyInto(out *EIPDNSRecord) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPDNSRecord.
+func (in *EIPDNSRecord) DeepCopy() *EIPDNSRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPDNSRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPInventory) DeepCopyInto(out *EIPInventory) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPInventory.
+func (in *EIPInventory) DeepCopy() *EIPInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EIPInventory) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPInventoryList) DeepCopyInto(out *EIPInventoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EIPInventory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPInventoryList.
+func (in *EIPInventoryList) DeepCopy() *EIPInventoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPInventoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EIPInventoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPInventorySpec) DeepCopyInto(out *EIPInventorySpec) {
+	*out = *in
+	if in.EIPSelector != nil {
+		in, out := &in.EIPSelector, &out.EIPSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterEIPSelector != nil {
+		in, out := &in.ClusterEIPSelector, &out.ClusterEIPSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPInventorySpec.
+func (in *EIPInventorySpec) DeepCopy() *EIPInventorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPInventorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPInventoryStatus) DeepCopyInto(out *EIPInventoryStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPInventoryStatus.
+func (in *EIPInventoryStatus) DeepCopy() *EIPInventoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPInventoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPList) DeepCopyInto(out *EIPList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EIP, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPList.
+func (in *EIPList) DeepCopy() *EIPList {
 	if in == nil {
 		return nil
 	}
-	out := new(EIP)
+	out := new(EIPList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EIP) DeepCopyObject() runtime.Object {
+func (in *EIPList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -59,40 +684,25 @@ func (in *EIP) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EIPAssignment) DeepCopyInto(out *EIPAssignment) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssignment.
-func (in *EIPAssignment) DeepCopy() *EIPAssignment {
-	if in == nil {
-		return nil
-	}
-	out := new(EIPAssignment)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EIPAssociation) DeepCopyInto(out *EIPAssociation) {
+func (in *EIPPolicy) DeepCopyInto(out *EIPPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociation.
-func (in *EIPAssociation) DeepCopy() *EIPAssociation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPPolicy.
+func (in *EIPPolicy) DeepCopy() *EIPPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(EIPAssociation)
+	out := new(EIPPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EIPAssociation) DeepCopyObject() runtime.Object {
+func (in *EIPPolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -100,31 +710,31 @@ func (in *EIPAssociation) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EIPAssociationList) DeepCopyInto(out *EIPAssociationList) {
+func (in *EIPPolicyList) DeepCopyInto(out *EIPPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]EIPAssociation, len(*in))
+		*out = make([]EIPPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationList.
-func (in *EIPAssociationList) DeepCopy() *EIPAssociationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPPolicyList.
+func (in *EIPPolicyList) DeepCopy() *EIPPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(EIPAssociationList)
+	out := new(EIPPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EIPAssociationList) DeepCopyObject() runtime.Object {
+func (in *EIPPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -132,57 +742,182 @@ func (in *EIPAssociationList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EIPAssociationSpec) DeepCopyInto(out *EIPAssociationSpec) {
+func (in *EIPPolicySpec) DeepCopyInto(out *EIPPolicySpec) {
 	*out = *in
-	if in.Assignment != nil {
-		in, out := &in.Assignment, &out.Assignment
-		*out = new(EIPAssignment)
+	if in.MaxEIPs != nil {
+		in, out := &in.MaxEIPs, &out.MaxEIPs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxENIs != nil {
+		in, out := &in.MaxENIs, &out.MaxENIs
+		*out = new(int32)
 		**out = **in
 	}
+	if in.AllowedPublicIPv4Pools != nil {
+		in, out := &in.AllowedPublicIPv4Pools, &out.AllowedPublicIPv4Pools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedSubnetIDs != nil {
+		in, out := &in.AllowedSubnetIDs, &out.AllowedSubnetIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedSecurityGroups != nil {
+		in, out := &in.AllowedSecurityGroups, &out.AllowedSecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredTags != nil {
+		in, out := &in.RequiredTags, &out.RequiredTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPAssociationSpec.
-func (in *EIPAssociationSpec) DeepCopy() *EIPAssociationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPPolicySpec.
+func (in *EIPPolicySpec) DeepCopy() *EIPPolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(EIPAssociationSpec)
+	out := new(EIPPolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EIPList) DeepCopyInto(out *EIPList) {
+func (in *EIPReference) DeepCopyInto(out *EIPReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPReference.
+func (in *EIPReference) DeepCopy() *EIPReference {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPReferenceGrant) DeepCopyInto(out *EIPReferenceGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPReferenceGrant.
+func (in *EIPReferenceGrant) DeepCopy() *EIPReferenceGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPReferenceGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EIPReferenceGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPReferenceGrantFrom) DeepCopyInto(out *EIPReferenceGrantFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPReferenceGrantFrom.
+func (in *EIPReferenceGrantFrom) DeepCopy() *EIPReferenceGrantFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPReferenceGrantFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPReferenceGrantList) DeepCopyInto(out *EIPReferenceGrantList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]EIP, len(*in))
+		*out = make([]EIPReferenceGrant, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPList.
-func (in *EIPList) DeepCopy() *EIPList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPReferenceGrantList.
+func (in *EIPReferenceGrantList) DeepCopy() *EIPReferenceGrantList {
 	if in == nil {
 		return nil
 	}
-	out := new(EIPList)
+	out := new(EIPReferenceGrantList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EIPList) DeepCopyObject() runtime.Object {
+func (in *EIPReferenceGrantList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPReferenceGrantSpec) DeepCopyInto(out *EIPReferenceGrantSpec) {
+	*out = *in
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]EIPReferenceGrantFrom, len(*in))
+		copy(*out, *in)
+	}
+	if in.EIPNames != nil {
+		in, out := &in.EIPNames, &out.EIPNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPReferenceGrantSpec.
+func (in *EIPReferenceGrantSpec) DeepCopy() *EIPReferenceGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPReferenceGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EIPReverseDNSStatus) DeepCopyInto(out *EIPReverseDNSStatus) {
+	*out = *in
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPReverseDNSStatus.
+func (in *EIPReverseDNSStatus) DeepCopy() *EIPReverseDNSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EIPReverseDNSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EIPSpec) DeepCopyInto(out *EIPSpec) {
 	*out = *in
@@ -196,6 +931,11 @@ func (in *EIPSpec) DeepCopyInto(out *EIPSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(EIPDNSRecord)
+		**out = **in
+	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = new(map[string]string)
@@ -222,11 +962,32 @@ func (in *EIPSpec) DeepCopy() *EIPSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EIPStatus) DeepCopyInto(out *EIPStatus) {
 	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Assignment != nil {
 		in, out := &in.Assignment, &out.Assignment
 		*out = new(EIPAssignment)
 		**out = **in
 	}
+	if in.ReverseDNS != nil {
+		in, out := &in.ReverseDNS, &out.ReverseDNS
+		*out = new(EIPReverseDNSStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(EIPDNSRecord)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EIPStatus.
@@ -269,6 +1030,21 @@ func (in *ENI) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ENIAttachment) DeepCopyInto(out *ENIAttachment) {
 	*out = *in
+	if in.DeviceIndex != nil {
+		in, out := &in.DeviceIndex, &out.DeviceIndex
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NetworkCardIndex != nil {
+		in, out := &in.NetworkCardIndex, &out.NetworkCardIndex
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DeleteOnTermination != nil {
+		in, out := &in.DeleteOnTermination, &out.DeleteOnTermination
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ENIAttachment.
@@ -313,6 +1089,131 @@ func (in *ENIList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ENIMultus) DeepCopyInto(out *ENIMultus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ENIMultus.
+func (in *ENIMultus) DeepCopy() *ENIMultus {
+	if in == nil {
+		return nil
+	}
+	out := new(ENIMultus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ENIPool) DeepCopyInto(out *ENIPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ENIPool.
+func (in *ENIPool) DeepCopy() *ENIPool {
+	if in == nil {
+		return nil
+	}
+	out := new(ENIPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ENIPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ENIPoolList) DeepCopyInto(out *ENIPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ENIPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ENIPoolList.
+func (in *ENIPoolList) DeepCopy() *ENIPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(ENIPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ENIPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ENIPoolSpec) DeepCopyInto(out *ENIPoolSpec) {
+	*out = *in
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = new(map[string]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make(map[string]string, len(*in))
+			for key, val := range *in {
+				(*out)[key] = val
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ENIPoolSpec.
+func (in *ENIPoolSpec) DeepCopy() *ENIPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ENIPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ENIPoolStatus) DeepCopyInto(out *ENIPoolStatus) {
+	*out = *in
+	if in.NetworkInterfaceIDs != nil {
+		in, out := &in.NetworkInterfaceIDs, &out.NetworkInterfaceIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ENIPoolStatus.
+func (in *ENIPoolStatus) DeepCopy() *ENIPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ENIPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ENISpec) DeepCopyInto(out *ENISpec) {
 	*out = *in
@@ -324,6 +1225,11 @@ func (in *ENISpec) DeepCopyInto(out *ENISpec) {
 	if in.Attachment != nil {
 		in, out := &in.Attachment, &out.Attachment
 		*out = new(ENIAttachment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SourceDestCheck != nil {
+		in, out := &in.SourceDestCheck, &out.SourceDestCheck
+		*out = new(bool)
 		**out = **in
 	}
 	if in.Tags != nil {
@@ -337,6 +1243,11 @@ func (in *ENISpec) DeepCopyInto(out *ENISpec) {
 			}
 		}
 	}
+	if in.Multus != nil {
+		in, out := &in.Multus, &out.Multus
+		*out = new(ENIMultus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ENISpec.
@@ -360,8 +1271,20 @@ func (in *ENIStatus) DeepCopyInto(out *ENIStatus) {
 	if in.Attachment != nil {
 		in, out := &in.Attachment, &out.Attachment
 		*out = new(ENIAttachment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SourceDestCheck != nil {
+		in, out := &in.SourceDestCheck, &out.SourceDestCheck
+		*out = new(bool)
 		**out = **in
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ENIStatus.
@@ -0,0 +1,175 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=awsaccounts,verbs=get;list;watch
+
+// EC2Clients creates EC2 clients for AWSAccounts and regions and caches
+// them, so that assumed role credentials are only refreshed when they expire.
+type EC2Clients struct {
+	Session *session.Session
+	// DryRun makes the clients plan mutating calls instead of executing
+	// them, see NewDryRunEC2.
+	DryRun bool
+
+	clients sync.Map
+}
+
+type ec2ClientKey struct {
+	roleARN, externalID, region string
+}
+
+// Get returns the EC2 client for the given AWSAccount, or the account of the
+// operator if account is nil, in the given region. If region is empty, the
+// region of the account, or else of the operator, is used. Changing the role,
+// external ID or region of an account results in a new client.
+//
+// The region the client is for is returned as well; it is empty for the
+// operator's region.
+func (c *EC2Clients) Get(account *awsv1alpha1.AWSAccount, region string) (ec2iface.EC2API, string) {
+	var key ec2ClientKey
+	if account != nil {
+		key = ec2ClientKey{
+			roleARN:    account.Spec.RoleARN,
+			externalID: account.Spec.ExternalID,
+			region:     account.Spec.Region,
+		}
+	}
+	if region != "" {
+		key.region = region
+	}
+	if ec2Client, ok := c.clients.Load(key); ok {
+		return ec2Client.(ec2iface.EC2API), key.region
+	}
+
+	config := aws.NewConfig()
+	if key.roleARN != "" {
+		config = config.WithCredentials(stscreds.NewCredentials(c.Session, key.roleARN, func(p *stscreds.AssumeRoleProvider) {
+			if key.externalID != "" {
+				p.ExternalID = aws.String(key.externalID)
+			}
+			p.RoleSessionName = "k8s-aws-operator"
+		}))
+	}
+	if key.region != "" {
+		config = config.WithRegion(key.region)
+	}
+
+	var newClient ec2iface.EC2API = ec2.New(c.Session, config)
+	if c.DryRun {
+		newClient = NewDryRunEC2(newClient)
+	}
+	ec2Client, _ := c.clients.LoadOrStore(key, newClient)
+	return ec2Client.(ec2iface.EC2API), key.region
+}
+
+// describeRegion describes a region returned by EC2Clients.Get in messages.
+func describeRegion(region string) string {
+	if region == "" {
+		return "the operator's region"
+	}
+	return "region " + region
+}
+
+// validateSubnetAndSecurityGroups checks that the subnet and security groups
+// of an ENI exist in the region of the client and belong to the same VPC, so
+// that ENIs referencing resources of another region fail with a clear error.
+func validateSubnetAndSecurityGroups(ctx context.Context, ec2Client ec2iface.EC2API, region, subnetID string, securityGroups []string) error {
+	subnets, err := ec2Client.DescribeSubnetsWithContext(ctx, &ec2.DescribeSubnetsInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("subnet-id"),
+			Values: []*string{aws.String(subnetID)},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	if len(subnets.Subnets) == 0 {
+		return fmt.Errorf("subnet %s not found in %s", subnetID, describeRegion(region))
+	}
+	vpcID := aws.StringValue(subnets.Subnets[0].VpcId)
+
+	if len(securityGroups) == 0 {
+		return nil
+	}
+	groups, err := ec2Client.DescribeSecurityGroupsWithContext(ctx, &ec2.DescribeSecurityGroupsInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("group-id"),
+			Values: aws.StringSlice(securityGroups),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool)
+	for _, group := range groups.SecurityGroups {
+		if aws.StringValue(group.VpcId) != vpcID {
+			return fmt.Errorf("security group %s is not in VPC %s of subnet %s", aws.StringValue(group.GroupId), vpcID, subnetID)
+		}
+		found[aws.StringValue(group.GroupId)] = true
+	}
+	for _, securityGroup := range securityGroups {
+		if !found[securityGroup] {
+			return fmt.Errorf("security group %s not found in %s", securityGroup, describeRegion(region))
+		}
+	}
+	return nil
+}
+
+// getAWSAccount returns the AWSAccount with the given name if resources in
+// the given namespace may use it. namespace is empty for cluster-scoped
+// resources.
+func getAWSAccount(ctx context.Context, c client.Reader, name, namespace string) (*awsv1alpha1.AWSAccount, error) {
+	var account awsv1alpha1.AWSAccount
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, &account); err != nil {
+		return nil, err
+	}
+
+	if namespace == "" || account.Spec.NamespaceSelector == nil {
+		return &account, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(account.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespaceSelector in AWSAccount %s: %w", name, err)
+	}
+	var ns corev1.Namespace
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		return nil, err
+	}
+	if !selector.Matches(labels.Set(ns.Labels)) {
+		return nil, fmt.Errorf("namespace %s may not use AWSAccount %s", namespace, name)
+	}
+	return &account, nil
+}
@@ -0,0 +1,263 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// conditionTypeSynced is the type of the condition telling whether the
+	// last reconciliation of an EIP or ENI with AWS succeeded.
+	conditionTypeSynced = "Synced"
+
+	reasonSynced         = "Synced"
+	reasonNotFound       = "NotFound"
+	reasonTransientError = "TransientError"
+	reasonDryRun         = "DryRun"
+
+	// notFoundRequeueInterval is how often objects whose AWS resource was
+	// not found are reconciled again.
+	notFoundRequeueInterval = 5 * time.Minute
+	// transientRequeueInterval is how often objects are reconciled again
+	// after a transient error.
+	transientRequeueInterval = 15 * time.Second
+)
+
+// awsNotFoundError is returned if an AWS resource does not exist, according
+// to the error code of AWS or because it is missing from the response.
+type awsNotFoundError struct {
+	resource string
+	id       string
+	err      error
+}
+
+func (e *awsNotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.resource, e.id)
+}
+
+func (e *awsNotFoundError) Unwrap() error {
+	return e.err
+}
+
+// awsTransientError wraps errors that are expected to go away when retrying,
+// i.e. throttling, server-side and network errors.
+type awsTransientError struct {
+	err error
+}
+
+func (e *awsTransientError) Error() string {
+	// without request ID, so that the condition does not change on every
+	// retry
+	var awsErr awserr.Error
+	if errors.As(e.err, &awsErr) {
+		return fmt.Sprintf("transient AWS error: %s: %s", awsErr.Code(), awsErr.Message())
+	}
+	return fmt.Sprintf("transient AWS error: %v", e.err)
+}
+
+func (e *awsTransientError) Unwrap() error {
+	return e.err
+}
+
+// isAWSErrorCode returns whether err is an AWS error with one of the codes.
+func isAWSErrorCode(err error, codes ...string) bool {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+	for _, code := range codes {
+		if awsErr.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyAWSError converts errors of AWS calls for the given resource into
+// awsNotFoundErrors and awsTransientErrors. Other errors are returned as is.
+func classifyAWSError(resource, id string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		// not returned by the SDK, e.g. a canceled context
+		return &awsTransientError{err}
+	}
+	if strings.HasSuffix(awsErr.Code(), ".NotFound") {
+		return &awsNotFoundError{resource: resource, id: id, err: err}
+	}
+	if isTransientAWSError(err) {
+		return &awsTransientError{err}
+	}
+	return err
+}
+
+// transientAWSError wraps err into an awsTransientError if it is a transient
+// error returned by AWS, and returns it unchanged otherwise.
+func transientAWSError(err error) error {
+	if isTransient(err) || !isTransientAWSError(err) {
+		return err
+	}
+	return &awsTransientError{err}
+}
+
+func isTransientAWSError(err error) bool {
+	if isThrottlingError(err) || isAWSErrorCode(err, "InternalError", "Unavailable",
+		request.ErrCodeRequestError, request.ErrCodeResponseTimeout, request.ErrCodeSerialization) {
+		return true
+	}
+	var reqErr awserr.RequestFailure
+	return errors.As(err, &reqErr) && reqErr.StatusCode() >= 500
+}
+
+func isThrottlingError(err error) bool {
+	return isAWSErrorCode(err, "RequestLimitExceeded", "Throttling", "ThrottlingException")
+}
+
+func isNotFound(err error) bool {
+	var notFound *awsNotFoundError
+	return errors.As(err, &notFound)
+}
+
+func isTransient(err error) bool {
+	var transient *awsTransientError
+	return errors.As(err, &transient)
+}
+
+func ignoreConflict(err error) error {
+	if apierrors.IsConflict(err) {
+		return nil
+	}
+	return err
+}
+
+// describeAddress returns the EIP with the given allocation ID.
+func describeAddress(ctx context.Context, ec2Client ec2iface.EC2API, allocationID string) (*ec2.Address, error) {
+	resp, err := ec2Client.DescribeAddressesWithContext(ctx, &ec2.DescribeAddressesInput{
+		AllocationIds: []*string{aws.String(allocationID)},
+	})
+	if err != nil {
+		return nil, classifyAWSError("allocation", allocationID, err)
+	}
+	if len(resp.Addresses) == 0 {
+		return nil, &awsNotFoundError{resource: "allocation", id: allocationID}
+	}
+	return resp.Addresses[0], nil
+}
+
+// describeNetworkInterface returns the ENI with the given ID.
+func describeNetworkInterface(ctx context.Context, ec2Client ec2iface.EC2API, networkInterfaceID string) (*ec2.NetworkInterface, error) {
+	resp, err := ec2Client.DescribeNetworkInterfacesWithContext(ctx, &ec2.DescribeNetworkInterfacesInput{
+		NetworkInterfaceIds: []*string{aws.String(networkInterfaceID)},
+	})
+	if err != nil {
+		return nil, classifyAWSError("network interface", networkInterfaceID, err)
+	}
+	if len(resp.NetworkInterfaces) == 0 {
+		return nil, &awsNotFoundError{resource: "network interface", id: networkInterfaceID}
+	}
+	return resp.NetworkInterfaces[0], nil
+}
+
+// describeInstance returns the EC2 instance with the given ID.
+func describeInstance(ctx context.Context, ec2Client ec2iface.EC2API, instanceID string) (*ec2.Instance, error) {
+	resp, err := ec2Client.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: []*string{aws.String(instanceID)},
+	})
+	if err != nil {
+		return nil, classifyAWSError("instance", instanceID, err)
+	}
+	if len(resp.Reservations) == 0 || len(resp.Reservations[0].Instances) == 0 {
+		return nil, &awsNotFoundError{resource: "instance", id: instanceID}
+	}
+	return resp.Reservations[0].Instances[0], nil
+}
+
+// setSyncedCondition sets the Synced condition according to the outcome of
+// a reconciliation and returns whether it changed. Errors other than
+// awsNotFoundErrors, awsTransientErrors and dryRunErrors do not change the
+// condition.
+func setSyncedCondition(conditions *[]metav1.Condition, generation int64, err error) bool {
+	condition := metav1.Condition{
+		Type:               conditionTypeSynced,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonSynced,
+		ObservedGeneration: generation,
+	}
+	switch {
+	case err == nil:
+		if meta.FindStatusCondition(*conditions, conditionTypeSynced) == nil {
+			// only recorded once something went wrong
+			return false
+		}
+	case isNotFound(err):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonNotFound
+		condition.Message = err.Error()
+	case isTransient(err):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonTransientError
+		condition.Message = err.Error()
+	case isDryRun(err):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonDryRun
+		condition.Message = err.Error()
+	default:
+		return false
+	}
+
+	if existing := meta.FindStatusCondition(*conditions, conditionTypeSynced); existing != nil &&
+		existing.Status == condition.Status && existing.Reason == condition.Reason &&
+		existing.Message == condition.Message && existing.ObservedGeneration == condition.ObservedGeneration {
+		return false
+	}
+	meta.SetStatusCondition(conditions, condition)
+	return true
+}
+
+// awsErrorResult returns the result of a reconciliation according to the
+// class of its error: objects are polled while their AWS resource is not
+// found, after server-side and network errors and in dry-run mode, and
+// retried with backoff otherwise. Throttling is retried with backoff too, so
+// that the operator does not keep AWS busy at a fixed rate.
+func awsErrorResult(result ctrl.Result, err error) (ctrl.Result, error) {
+	switch {
+	case isNotFound(err):
+		return ctrl.Result{RequeueAfter: notFoundRequeueInterval}, nil
+	case isTransient(err) && !isThrottlingError(err):
+		return ctrl.Result{RequeueAfter: transientRequeueInterval}, nil
+	case isDryRun(err):
+		return ctrl.Result{RequeueAfter: dryRunRequeueInterval}, nil
+	default:
+		return result, err
+	}
+}
@@ -0,0 +1,186 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type fakeEC2Describer struct {
+	ec2iface.EC2API
+	addresses         []*ec2.Address
+	networkInterfaces []*ec2.NetworkInterface
+	reservations      []*ec2.Reservation
+	err               error
+}
+
+func (f *fakeEC2Describer) DescribeAddressesWithContext(ctx aws.Context, input *ec2.DescribeAddressesInput, opts ...request.Option) (*ec2.DescribeAddressesOutput, error) {
+	return &ec2.DescribeAddressesOutput{Addresses: f.addresses}, f.err
+}
+
+func (f *fakeEC2Describer) DescribeNetworkInterfacesWithContext(ctx aws.Context, input *ec2.DescribeNetworkInterfacesInput, opts ...request.Option) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	return &ec2.DescribeNetworkInterfacesOutput{NetworkInterfaces: f.networkInterfaces}, f.err
+}
+
+func (f *fakeEC2Describer) DescribeInstancesWithContext(ctx aws.Context, input *ec2.DescribeInstancesInput, opts ...request.Option) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{Reservations: f.reservations}, f.err
+}
+
+func TestClassifyAWSError(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		err           error
+		wantNotFound  bool
+		wantTransient bool
+	}{
+		{"nil", nil, false, false},
+		{"not found", awserr.New("InvalidAllocationID.NotFound", "not found", nil), true, false},
+		{"wrapped not found", fmt.Errorf("describing: %w", awserr.New("InvalidNetworkInterfaceID.NotFound", "not found", nil)), true, false},
+		{"throttled", awserr.New("RequestLimitExceeded", "slow down", nil), false, true},
+		{"server error", awserr.NewRequestFailure(awserr.New("InternalFailure", "oops", nil), 503, "id"), false, true},
+		{"network error", awserr.New(request.ErrCodeRequestError, "send request failed", nil), false, true},
+		{"non-AWS error", context.DeadlineExceeded, false, true},
+		{"client error", awserr.NewRequestFailure(awserr.New("UnauthorizedOperation", "denied", nil), 403, "id"), false, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := classifyAWSError("allocation", "eipalloc-1", test.err)
+			if (err == nil) != (test.err == nil) {
+				t.Fatalf("got %v for %v", err, test.err)
+			}
+			if isNotFound(err) != test.wantNotFound {
+				t.Errorf("isNotFound(%v) = %t, want %t", err, !test.wantNotFound, test.wantNotFound)
+			}
+			if isTransient(err) != test.wantTransient {
+				t.Errorf("isTransient(%v) = %t, want %t", err, !test.wantTransient, test.wantTransient)
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Errorf("%v does not wrap %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestIsAWSErrorCode(t *testing.T) {
+	err := awserr.New("InvalidAssociationID.NotFound", "not found", nil)
+	if !isAWSErrorCode(err, "InvalidNetworkInterfaceID.NotFound", "InvalidAssociationID.NotFound") {
+		t.Error("code not matched")
+	}
+	if isAWSErrorCode(err, "InvalidAllocationID.NotFound") {
+		t.Error("other code matched")
+	}
+	if isAWSErrorCode(errors.New("InvalidAssociationID.NotFound"), "InvalidAssociationID.NotFound") {
+		t.Error("non-AWS error matched")
+	}
+	if isAWSErrorCode(nil, "InvalidAssociationID.NotFound") {
+		t.Error("nil error matched")
+	}
+}
+
+func TestDescribeEmptyResults(t *testing.T) {
+	ctx := context.Background()
+	empty := &fakeEC2Describer{}
+	if _, err := describeAddress(ctx, empty, "eipalloc-1"); !isNotFound(err) {
+		t.Errorf("describeAddress: got %v, want not found", err)
+	}
+	if _, err := describeNetworkInterface(ctx, empty, "eni-1"); !isNotFound(err) {
+		t.Errorf("describeNetworkInterface: got %v, want not found", err)
+	}
+	if _, err := describeInstance(ctx, empty, "i-1"); !isNotFound(err) {
+		t.Errorf("describeInstance: got %v, want not found", err)
+	}
+	if _, err := describeInstance(ctx, &fakeEC2Describer{reservations: []*ec2.Reservation{{}}}, "i-1"); !isNotFound(err) {
+		t.Errorf("describeInstance without instances: got %v, want not found", err)
+	}
+
+	throttled := &fakeEC2Describer{err: awserr.New("Throttling", "rate exceeded", nil)}
+	if _, err := describeAddress(ctx, throttled, "eipalloc-1"); !isTransient(err) {
+		t.Errorf("describeAddress: got %v, want transient", err)
+	}
+
+	found := &fakeEC2Describer{addresses: []*ec2.Address{{AllocationId: aws.String("eipalloc-1")}}}
+	if addr, err := describeAddress(ctx, found, "eipalloc-1"); err != nil || aws.StringValue(addr.AllocationId) != "eipalloc-1" {
+		t.Errorf("describeAddress: got %v, %v", addr, err)
+	}
+}
+
+func TestSetSyncedCondition(t *testing.T) {
+	var conditions []metav1.Condition
+	if setSyncedCondition(&conditions, 1, nil) {
+		t.Error("condition set without prior error")
+	}
+	if setSyncedCondition(&conditions, 1, errors.New("invalid spec")) {
+		t.Error("condition set for unclassified error")
+	}
+
+	notFound := &awsNotFoundError{resource: "allocation", id: "eipalloc-1"}
+	if !setSyncedCondition(&conditions, 1, notFound) {
+		t.Fatal("not found error not recorded")
+	}
+	if c := conditions[0]; c.Status != metav1.ConditionFalse || c.Reason != reasonNotFound {
+		t.Errorf("got %+v", c)
+	}
+	if setSyncedCondition(&conditions, 1, notFound) {
+		t.Error("unchanged condition updated")
+	}
+
+	if !setSyncedCondition(&conditions, 1, &awsTransientError{awserr.New("Throttling", "rate exceeded", nil)}) {
+		t.Fatal("transient error not recorded")
+	}
+	if c := conditions[0]; c.Reason != reasonTransientError {
+		t.Errorf("got %+v", c)
+	}
+
+	if !setSyncedCondition(&conditions, 1, nil) {
+		t.Fatal("recovery not recorded")
+	}
+	if len(conditions) != 1 || conditions[0].Status != metav1.ConditionTrue {
+		t.Errorf("got %+v", conditions)
+	}
+}
+
+func TestAWSErrorResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantRequeue bool
+		wantErr     bool
+	}{
+		{"success", nil, false, false},
+		{"not found", &awsNotFoundError{resource: "allocation", id: "eipalloc-1"}, true, false},
+		{"server error", &awsTransientError{awserr.New("InternalError", "internal error", nil)}, true, false},
+		{"throttling", &awsTransientError{awserr.New("RequestLimitExceeded", "rate exceeded", nil)}, false, true},
+		{"other", errors.New("invalid spec"), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := awsErrorResult(ctrl.Result{}, tt.err)
+			if (result.RequeueAfter > 0) != tt.wantRequeue || (err != nil) != tt.wantErr {
+				t.Errorf("got %+v, %v", result, err)
+			}
+		})
+	}
+}
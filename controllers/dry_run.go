@@ -0,0 +1,286 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+	"github.com/go-logr/logr"
+)
+
+// dryRunRequeueInterval is how often objects are reconciled again in dry-run
+// mode, so that the permission checks of planned actions are repeated.
+const dryRunRequeueInterval = 5 * time.Minute
+
+// dryRunError is returned instead of executing mutating AWS calls in dry-run
+// mode. It describes the planned action, and the outcome of checking it with
+// the DryRun parameter if the operation supports it.
+type dryRunError struct {
+	operation string
+	input     interface{}
+	checked   bool
+	// err is the error of the check, nil if the call would have been
+	// permitted
+	err error
+}
+
+func newDryRunError(operation string, input interface{}, checkErr error) *dryRunError {
+	if isAWSErrorCode(checkErr, "DryRunOperation") {
+		checkErr = nil
+	}
+	return &dryRunError{operation: operation, input: input, checked: true, err: checkErr}
+}
+
+// newPlannedWrite returns a dryRunError describing a write to Kubernetes
+// that is skipped in dry-run mode because it would delete or change resources
+// other than the reconciled one. Such writes cannot be checked.
+func newPlannedWrite(operation string, input interface{}) *dryRunError {
+	return &dryRunError{operation: operation, input: input}
+}
+
+func (e *dryRunError) Error() string {
+	msg := "dry run: would call " + e.operation
+	if !e.checked {
+		return msg
+	}
+	if e.err == nil {
+		return msg + ", which is permitted"
+	}
+	var awsErr awserr.Error
+	if errors.As(e.err, &awsErr) {
+		return fmt.Sprintf("%s, which would fail with %s", msg, awsErr.Code())
+	}
+	return fmt.Sprintf("%s, which could not be checked: %v", msg, e.err)
+}
+
+func isDryRun(err error) bool {
+	var dryRun *dryRunError
+	return errors.As(err, &dryRun)
+}
+
+// logPlannedAction logs the action planned instead of a mutating AWS call in
+// dry-run mode, if err is a dryRunError.
+func logPlannedAction(log logr.Logger, err error) {
+	var dryRun *dryRunError
+	if !errors.As(err, &dryRun) {
+		return
+	}
+	log.Info("planned action", "operation", dryRun.operation, "input", dryRun.input,
+		"checked", dryRun.checked, "checkError", dryRun.err)
+}
+
+// dryRunEC2 plans mutating EC2 calls instead of executing them. Calls
+// supporting the DryRun parameter are made with it, to check whether they
+// would be permitted. Read-only calls are passed through.
+type dryRunEC2 struct {
+	ec2iface.EC2API
+}
+
+// NewDryRunEC2 returns an EC2 client that returns dry-run errors describing
+// mutating calls instead of executing them.
+func NewDryRunEC2(ec2Client ec2iface.EC2API) ec2iface.EC2API {
+	return &dryRunEC2{ec2Client}
+}
+
+func (c *dryRunEC2) AllocateAddress(input *ec2.AllocateAddressInput) (*ec2.AllocateAddressOutput, error) {
+	return c.AllocateAddressWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) AllocateAddressWithContext(ctx aws.Context, input *ec2.AllocateAddressInput, opts ...request.Option) (*ec2.AllocateAddressOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.AllocateAddressWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("AllocateAddress", input, err)
+}
+
+func (c *dryRunEC2) AssociateAddress(input *ec2.AssociateAddressInput) (*ec2.AssociateAddressOutput, error) {
+	return c.AssociateAddressWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) AssociateAddressWithContext(ctx aws.Context, input *ec2.AssociateAddressInput, opts ...request.Option) (*ec2.AssociateAddressOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.AssociateAddressWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("AssociateAddress", input, err)
+}
+
+func (c *dryRunEC2) AttachNetworkInterface(input *ec2.AttachNetworkInterfaceInput) (*ec2.AttachNetworkInterfaceOutput, error) {
+	return c.AttachNetworkInterfaceWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) AttachNetworkInterfaceWithContext(ctx aws.Context, input *ec2.AttachNetworkInterfaceInput, opts ...request.Option) (*ec2.AttachNetworkInterfaceOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.AttachNetworkInterfaceWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("AttachNetworkInterface", input, err)
+}
+
+func (c *dryRunEC2) CreateNetworkInterface(input *ec2.CreateNetworkInterfaceInput) (*ec2.CreateNetworkInterfaceOutput, error) {
+	return c.CreateNetworkInterfaceWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) CreateNetworkInterfaceWithContext(ctx aws.Context, input *ec2.CreateNetworkInterfaceInput, opts ...request.Option) (*ec2.CreateNetworkInterfaceOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.CreateNetworkInterfaceWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("CreateNetworkInterface", input, err)
+}
+
+func (c *dryRunEC2) CreateTags(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	return c.CreateTagsWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) CreateTagsWithContext(ctx aws.Context, input *ec2.CreateTagsInput, opts ...request.Option) (*ec2.CreateTagsOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.CreateTagsWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("CreateTags", input, err)
+}
+
+func (c *dryRunEC2) DeleteNetworkInterface(input *ec2.DeleteNetworkInterfaceInput) (*ec2.DeleteNetworkInterfaceOutput, error) {
+	return c.DeleteNetworkInterfaceWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) DeleteNetworkInterfaceWithContext(ctx aws.Context, input *ec2.DeleteNetworkInterfaceInput, opts ...request.Option) (*ec2.DeleteNetworkInterfaceOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.DeleteNetworkInterfaceWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("DeleteNetworkInterface", input, err)
+}
+
+func (c *dryRunEC2) DeleteTags(input *ec2.DeleteTagsInput) (*ec2.DeleteTagsOutput, error) {
+	return c.DeleteTagsWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) DeleteTagsWithContext(ctx aws.Context, input *ec2.DeleteTagsInput, opts ...request.Option) (*ec2.DeleteTagsOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.DeleteTagsWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("DeleteTags", input, err)
+}
+
+func (c *dryRunEC2) DetachNetworkInterface(input *ec2.DetachNetworkInterfaceInput) (*ec2.DetachNetworkInterfaceOutput, error) {
+	return c.DetachNetworkInterfaceWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) DetachNetworkInterfaceWithContext(ctx aws.Context, input *ec2.DetachNetworkInterfaceInput, opts ...request.Option) (*ec2.DetachNetworkInterfaceOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.DetachNetworkInterfaceWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("DetachNetworkInterface", input, err)
+}
+
+func (c *dryRunEC2) DisassociateAddress(input *ec2.DisassociateAddressInput) (*ec2.DisassociateAddressOutput, error) {
+	return c.DisassociateAddressWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) DisassociateAddressWithContext(ctx aws.Context, input *ec2.DisassociateAddressInput, opts ...request.Option) (*ec2.DisassociateAddressOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.DisassociateAddressWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("DisassociateAddress", input, err)
+}
+
+func (c *dryRunEC2) ModifyAddressAttribute(input *ec2.ModifyAddressAttributeInput) (*ec2.ModifyAddressAttributeOutput, error) {
+	return c.ModifyAddressAttributeWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) ModifyAddressAttributeWithContext(ctx aws.Context, input *ec2.ModifyAddressAttributeInput, opts ...request.Option) (*ec2.ModifyAddressAttributeOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.ModifyAddressAttributeWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("ModifyAddressAttribute", input, err)
+}
+
+func (c *dryRunEC2) ModifyNetworkInterfaceAttribute(input *ec2.ModifyNetworkInterfaceAttributeInput) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
+	return c.ModifyNetworkInterfaceAttributeWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) ModifyNetworkInterfaceAttributeWithContext(ctx aws.Context, input *ec2.ModifyNetworkInterfaceAttributeInput, opts ...request.Option) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.ModifyNetworkInterfaceAttributeWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("ModifyNetworkInterfaceAttribute", input, err)
+}
+
+func (c *dryRunEC2) ReleaseAddress(input *ec2.ReleaseAddressInput) (*ec2.ReleaseAddressOutput, error) {
+	return c.ReleaseAddressWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) ReleaseAddressWithContext(ctx aws.Context, input *ec2.ReleaseAddressInput, opts ...request.Option) (*ec2.ReleaseAddressOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.ReleaseAddressWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("ReleaseAddress", input, err)
+}
+
+func (c *dryRunEC2) ResetAddressAttribute(input *ec2.ResetAddressAttributeInput) (*ec2.ResetAddressAttributeOutput, error) {
+	return c.ResetAddressAttributeWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) ResetAddressAttributeWithContext(ctx aws.Context, input *ec2.ResetAddressAttributeInput, opts ...request.Option) (*ec2.ResetAddressAttributeOutput, error) {
+	dryRunInput := *input
+	dryRunInput.DryRun = aws.Bool(true)
+	_, err := c.EC2API.ResetAddressAttributeWithContext(ctx, &dryRunInput, opts...)
+	return nil, newDryRunError("ResetAddressAttribute", input, err)
+}
+
+// AssignPrivateIpAddresses does not support the DryRun parameter.
+func (c *dryRunEC2) AssignPrivateIpAddresses(input *ec2.AssignPrivateIpAddressesInput) (*ec2.AssignPrivateIpAddressesOutput, error) {
+	return c.AssignPrivateIpAddressesWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) AssignPrivateIpAddressesWithContext(ctx aws.Context, input *ec2.AssignPrivateIpAddressesInput, opts ...request.Option) (*ec2.AssignPrivateIpAddressesOutput, error) {
+	return nil, &dryRunError{operation: "AssignPrivateIpAddresses", input: input}
+}
+
+// UnassignPrivateIpAddresses does not support the DryRun parameter.
+func (c *dryRunEC2) UnassignPrivateIpAddresses(input *ec2.UnassignPrivateIpAddressesInput) (*ec2.UnassignPrivateIpAddressesOutput, error) {
+	return c.UnassignPrivateIpAddressesWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunEC2) UnassignPrivateIpAddressesWithContext(ctx aws.Context, input *ec2.UnassignPrivateIpAddressesInput, opts ...request.Option) (*ec2.UnassignPrivateIpAddressesOutput, error) {
+	return nil, &dryRunError{operation: "UnassignPrivateIpAddresses", input: input}
+}
+
+// dryRunRoute53 plans changes of Route 53 records instead of executing them.
+// Route 53 has no DryRun parameter, so they are not checked.
+type dryRunRoute53 struct {
+	route53iface.Route53API
+}
+
+// NewDryRunRoute53 returns a Route 53 client that returns dry-run errors
+// describing changes of records instead of executing them.
+func NewDryRunRoute53(route53Client route53iface.Route53API) route53iface.Route53API {
+	return &dryRunRoute53{route53Client}
+}
+
+func (c *dryRunRoute53) ChangeResourceRecordSets(input *route53.ChangeResourceRecordSetsInput) (*route53.ChangeResourceRecordSetsOutput, error) {
+	return c.ChangeResourceRecordSetsWithContext(aws.BackgroundContext(), input)
+}
+
+func (c *dryRunRoute53) ChangeResourceRecordSetsWithContext(ctx aws.Context, input *route53.ChangeResourceRecordSetsInput, opts ...request.Option) (*route53.ChangeResourceRecordSetsOutput, error) {
+	return nil, &dryRunError{operation: "ChangeResourceRecordSets", input: input}
+}
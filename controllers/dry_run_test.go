@@ -0,0 +1,117 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/route53"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// fakeDryRunEC2 only implements AllocateAddressWithContext; calling any other
+// method panics.
+type fakeDryRunEC2 struct {
+	ec2iface.EC2API
+	input *ec2.AllocateAddressInput
+	err   error
+}
+
+func (f *fakeDryRunEC2) AllocateAddressWithContext(ctx aws.Context, input *ec2.AllocateAddressInput, opts ...request.Option) (*ec2.AllocateAddressOutput, error) {
+	f.input = input
+	return &ec2.AllocateAddressOutput{AllocationId: aws.String("eipalloc-1")}, f.err
+}
+
+func TestDryRunEC2(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{"permitted", awserr.New("DryRunOperation", "Request would have succeeded", nil), "dry run: would call AllocateAddress, which is permitted"},
+		{"not permitted", awserr.New("UnauthorizedOperation", "not authorized", nil), "dry run: would call AllocateAddress, which would fail with UnauthorizedOperation"},
+		{"check failed", errors.New("connection refused"), "dry run: would call AllocateAddress, which could not be checked: connection refused"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeDryRunEC2{err: test.err}
+			input := &ec2.AllocateAddressInput{Domain: aws.String("vpc")}
+			resp, err := NewDryRunEC2(fake).AllocateAddress(input)
+			if resp != nil {
+				t.Errorf("got response %v", resp)
+			}
+			if !isDryRun(err) {
+				t.Fatalf("got error %v, want dry run", err)
+			}
+			if err.Error() != test.message {
+				t.Errorf("got message %q, want %q", err.Error(), test.message)
+			}
+			if !aws.BoolValue(fake.input.DryRun) {
+				t.Error("DryRun parameter not set")
+			}
+			if input.DryRun != nil {
+				t.Error("input of the caller modified")
+			}
+		})
+	}
+}
+
+func TestDryRunWithoutCheck(t *testing.T) {
+	// the fake panics if the calls are passed through
+	ec2Client := NewDryRunEC2(&fakeDryRunEC2{})
+	if _, err := ec2Client.AssignPrivateIpAddresses(&ec2.AssignPrivateIpAddressesInput{}); err == nil || err.Error() != "dry run: would call AssignPrivateIpAddresses" {
+		t.Errorf("got %v", err)
+	}
+	if _, err := NewDryRunRoute53(nil).ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{}); !isDryRun(err) {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestPlannedClaim(t *testing.T) {
+	eni := &awsv1alpha1.ENI{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "eni-1"},
+		Spec:       awsv1alpha1.ENISpec{PoolName: "pool"},
+	}
+	err := newPlannedClaim(eni, "eni-0123")
+	if !isDryRun(err) || err.Error() != "dry run: would call Update" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestDryRunMultus(t *testing.T) {
+	subnetCIDRs := &sync.Map{}
+	subnetCIDRs.Store("subnet-1", "10.0.0.0/24")
+	r := &ENIReconciler{NonCachingClient: fake.NewClientBuilder().Build(), DryRun: true, subnetCIDRs: subnetCIDRs}
+	eni := &awsv1alpha1.ENI{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "eni"},
+		Spec:       awsv1alpha1.ENISpec{SubnetID: "subnet-1", Multus: &awsv1alpha1.ENIMultus{}},
+		Status:     awsv1alpha1.ENIStatus{MacAddress: "02:00:00:00:00:01", PrivateIPAddresses: []string{"10.0.0.10"}},
+	}
+
+	if err := r.reconcileNetworkAttachmentDefinition(context.Background(), eni); !isDryRun(err) || err.Error() != "dry run: would call Create" {
+		t.Errorf("got %v", err)
+	}
+}
@@ -18,11 +18,25 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/go-logr/logr"
 	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// eipRefField indexes associations by the namespace and name of the EIP
+	// they reference.
+	eipRefField = ".spec.eipRef"
 )
 
 // EIPReconciler reconciles a EIP object
@@ -32,6 +46,7 @@ type EIPAssociationReconciler struct {
 }
 
 // +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=eipassociations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=eipreferencegrants,verbs=get;list;watch
 
 func (r *EIPAssociationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("eipAssociation", req.NamespacedName)
@@ -43,46 +58,107 @@ func (r *EIPAssociationReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	if eipAssociation.ObjectMeta.DeletionTimestamp.IsZero() {
 		if !containsString(eipAssociation.ObjectMeta.Finalizers, finalizerName) {
-			eipAssociation.ObjectMeta.Finalizers = append(eipAssociation.ObjectMeta.Finalizers, finalizerName)
 			log.Info("New EIP Association")
-			var eip awsv1alpha1.EIP
-			if err := r.Client.Get(ctx, client.ObjectKey{
-				Namespace: req.Namespace,
-				Name:      eipAssociation.Spec.EIPName,
-			}, &eip); err != nil {
+			eipAssociation.ObjectMeta.Finalizers = append(eipAssociation.ObjectMeta.Finalizers, finalizerName)
+			eipAssociation.Status.Phase = awsv1alpha1.EIPAssociationPhasePending
+			return ctrl.Result{}, r.Update(ctx, &eipAssociation)
+		}
+
+		key := getEIPKey(&eipAssociation)
+		permitted, err := r.isPermitted(ctx, &eipAssociation, key)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !permitted {
+			// the grant might have been revoked after the EIP was bound
+			if err := r.releaseEIP(ctx, &eipAssociation, log); err != nil {
 				return ctrl.Result{}, err
 			}
+			return ctrl.Result{}, r.setPhase(ctx, &eipAssociation, r.unboundPhase(&eipAssociation, awsv1alpha1.EIPAssociationPhasePending), "RefNotPermitted",
+				fmt.Sprintf("no EIPReferenceGrant in namespace %s permits referencing EIP %s", key.Namespace, key.Name))
+		}
+
+		assignment := getAssignment(&eipAssociation, key.Namespace)
+		if binding := eipAssociation.Status.Binding; binding != nil && !isBindingCurrent(binding, key, assignment) {
+			// the association changed since it bound the EIP
+			if err := r.updateBoundEIP(ctx, &eipAssociation, getRebindAssignment(binding, key, assignment), log); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		var eip awsv1alpha1.EIP
+		if err := r.Client.Get(ctx, key, &eip); err != nil {
+			if apierrors.IsNotFound(err) {
+				return ctrl.Result{}, r.setPhase(ctx, &eipAssociation, r.unboundPhase(&eipAssociation, awsv1alpha1.EIPAssociationPhasePending), "EIPNotFound",
+					fmt.Sprintf("EIP %s does not exist", key))
+			}
+			return ctrl.Result{}, err
+		}
+
+		if eipAssociation.Spec.Assignment == nil {
+			return ctrl.Result{}, r.setPhase(ctx, &eipAssociation, awsv1alpha1.EIPAssociationPhasePending, "NoAssignment",
+				"no assignment given")
+		}
+
+		queue, err := r.getQueue(ctx, &eip)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if eip.Spec.Assignment == nil {
+			if !eip.ObjectMeta.DeletionTimestamp.IsZero() || !isBindable(&eip) {
+				return ctrl.Result{}, r.setPhase(ctx, &eipAssociation, r.unboundPhase(&eipAssociation, awsv1alpha1.EIPAssociationPhasePending), "EIPNotAllocated",
+					fmt.Sprintf("EIP %s is in state %q", eip.Name, eip.Status.State))
+			}
 
-			if eip.Spec.Assignment == nil && eip.Status.State == "allocated" {
-				eip.Spec.Assignment = eipAssociation.Spec.Assignment
-			} else {
-				return ctrl.Result{}, fmt.Errorf("Cannot assign EIP because it isn't in allocated state.")
+			if len(queue) > 0 && queue[0].UID != eipAssociation.UID {
+				return ctrl.Result{}, r.setPhase(ctx, &eipAssociation, r.unboundPhase(&eipAssociation, awsv1alpha1.EIPAssociationPhasePending), "Queued",
+					fmt.Sprintf("EIP %s is free, but EIPAssociation %s takes precedence", eip.Name, queue[0].Name))
 			}
 
+			// EIP is free, (re-)bind it; the binding is recorded first, so
+			// that the EIP is released even if the association changes
+			if err := r.setBinding(ctx, &eipAssociation, newBinding(key, assignment)); err != nil {
+				return ctrl.Result{}, err
+			}
+			log.Info("Assigning EIP", "eip", key)
+			eip.Spec.Assignment = assignment
 			if err := r.Update(ctx, &eip); err != nil {
 				return ctrl.Result{}, err
 			}
+			return ctrl.Result{}, r.setPhase(ctx, &eipAssociation, awsv1alpha1.EIPAssociationPhasePending, "Assigning",
+				fmt.Sprintf("EIP %s is being assigned", eip.Name))
+		}
 
-			return ctrl.Result{}, r.Update(ctx, &eipAssociation)
+		if !isSameAssignment(eip.Spec.Assignment, assignment) {
+			for _, holder := range queue {
+				if isSameAssignment(eip.Spec.Assignment, getAssignment(&holder, eip.Namespace)) {
+					return ctrl.Result{}, r.setPhase(ctx, &eipAssociation, r.unboundPhase(&eipAssociation, awsv1alpha1.EIPAssociationPhasePending), "Queued",
+						fmt.Sprintf("EIP %s is held by EIPAssociation %s", eip.Name, holder.Name))
+				}
+			}
+			return ctrl.Result{}, r.setPhase(ctx, &eipAssociation, r.unboundPhase(&eipAssociation, awsv1alpha1.EIPAssociationPhaseConflict), "AssignedElsewhere",
+				fmt.Sprintf("EIP %s is assigned to something else", eip.Name))
+		}
+
+		// also records the binding of associations bound by earlier versions
+		if err := r.setBinding(ctx, &eipAssociation, newBinding(key, assignment)); err != nil {
+			return ctrl.Result{}, err
 		}
+
+		if eip.Status.State == awsv1alpha1.EIPStateAssigned && isSameAssignment(eip.Status.Assignment, assignment) {
+			return ctrl.Result{}, r.setPhase(ctx, &eipAssociation, awsv1alpha1.EIPAssociationPhaseBound, "Assigned",
+				fmt.Sprintf("EIP %s is assigned", eip.Name))
+		}
+
+		return ctrl.Result{}, r.setPhase(ctx, &eipAssociation, awsv1alpha1.EIPAssociationPhasePending, "Assigning",
+			fmt.Sprintf("EIP %s is being assigned (state %q)", eip.Name, eip.Status.State))
 	} else {
 		// Association is being deleted we want to unassign EIP
 		if containsString(eipAssociation.ObjectMeta.Finalizers, finalizerName) {
-			var eip awsv1alpha1.EIP
-			if err := r.Client.Get(ctx, client.ObjectKey{
-				Namespace: req.Namespace,
-				Name:      eipAssociation.Spec.EIPName,
-			}, &eip); err != nil {
+			if err := r.releaseEIP(ctx, &eipAssociation, log); err != nil {
 				return ctrl.Result{}, err
 			}
-
-			if eip.Status.Assignment != nil && (eip.Status.Assignment.PodName == eipAssociation.Spec.Assignment.PodName || eip.Status.Assignment.ENI == eipAssociation.Spec.Assignment.ENI) {
-				log.Info("Unassigning corresponding EIP")
-				eip.Spec.Assignment = nil
-				if err := r.Update(ctx, &eip); err != nil {
-					return ctrl.Result{}, err
-				}
-			}
 			eipAssociation.ObjectMeta.Finalizers = removeString(eipAssociation.ObjectMeta.Finalizers, finalizerName)
 			return ctrl.Result{}, r.Update(ctx, &eipAssociation)
 		}
@@ -91,8 +167,332 @@ func (r *EIPAssociationReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	return ctrl.Result{}, nil
 }
 
+// releaseEIP unassigns the EIP bound by an association, see updateBoundEIP.
+func (r *EIPAssociationReconciler) releaseEIP(ctx context.Context, eipAssociation *awsv1alpha1.EIPAssociation, log logr.Logger) error {
+	return r.updateBoundEIP(ctx, eipAssociation, nil, log)
+}
+
+// updateBoundEIP changes the assignment of the EIP bound by an association
+// to the given one, or unassigns it if nil, and records the new binding. The
+// EIP is left alone if it is not assigned as bound anymore, or if another
+// association asks for the very same assignment. Associations bound by
+// earlier versions, without a recorded binding, are assumed to be bound as
+// given in their spec.
+func (r *EIPAssociationReconciler) updateBoundEIP(ctx context.Context, eipAssociation *awsv1alpha1.EIPAssociation, assignment *awsv1alpha1.EIPAssignment, log logr.Logger) error {
+	binding := eipAssociation.Status.Binding
+	if binding == nil {
+		if eipAssociation.Spec.Assignment == nil {
+			return nil
+		}
+		key := getEIPKey(eipAssociation)
+		binding = newBinding(key, getAssignment(eipAssociation, key.Namespace))
+	}
+	key := types.NamespacedName{Namespace: binding.EIPRef.Namespace, Name: binding.EIPRef.Name}
+
+	var eip awsv1alpha1.EIP
+	if err := r.Client.Get(ctx, key, &eip); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return r.setBinding(ctx, eipAssociation, nil)
+	}
+	if !isSameAssignment(eip.Spec.Assignment, binding.Assignment) {
+		// assigned to something else since
+		return r.setBinding(ctx, eipAssociation, nil)
+	}
+
+	queue, err := r.getQueue(ctx, &eip)
+	if err != nil {
+		return err
+	}
+	for _, other := range queue {
+		if other.UID != eipAssociation.UID && isSameAssignment(binding.Assignment, getAssignment(&other, eip.Namespace)) {
+			// left to the other association
+			return r.setBinding(ctx, eipAssociation, nil)
+		}
+	}
+
+	if assignment == nil {
+		log.Info("Unassigning corresponding EIP", "eip", key)
+	} else {
+		log.Info("Reassigning corresponding EIP", "eip", key)
+	}
+	eip.Spec.Assignment = assignment
+	if err := r.Update(ctx, &eip); err != nil {
+		return err
+	}
+	if assignment == nil {
+		return r.setBinding(ctx, eipAssociation, nil)
+	}
+	return r.setBinding(ctx, eipAssociation, newBinding(key, assignment))
+}
+
+// setBinding records the binding of an association, if it changed.
+func (r *EIPAssociationReconciler) setBinding(ctx context.Context, eipAssociation *awsv1alpha1.EIPAssociation, binding *awsv1alpha1.EIPAssociationBinding) error {
+	existing := eipAssociation.Status.Binding
+	if existing == nil && binding == nil ||
+		existing != nil && binding != nil && existing.EIPRef == binding.EIPRef && isSameAssignment(existing.Assignment, binding.Assignment) {
+		return nil
+	}
+	eipAssociation.Status.Binding = binding
+	return r.Update(ctx, eipAssociation)
+}
+
+func newBinding(key types.NamespacedName, assignment *awsv1alpha1.EIPAssignment) *awsv1alpha1.EIPAssociationBinding {
+	return &awsv1alpha1.EIPAssociationBinding{
+		EIPRef:     awsv1alpha1.EIPReference{Namespace: key.Namespace, Name: key.Name},
+		Assignment: assignment,
+	}
+}
+
+// isBindingCurrent returns whether an association still references the EIP
+// it bound, with the same assignment.
+func isBindingCurrent(binding *awsv1alpha1.EIPAssociationBinding, key types.NamespacedName, assignment *awsv1alpha1.EIPAssignment) bool {
+	return binding.EIPRef.Namespace == key.Namespace && binding.EIPRef.Name == key.Name && isSameAssignment(binding.Assignment, assignment)
+}
+
+// getRebindAssignment returns the assignment to set on the EIP bound by an
+// association that changed: the new assignment if the association still
+// references the EIP, and nil to release it otherwise.
+func getRebindAssignment(binding *awsv1alpha1.EIPAssociationBinding, key types.NamespacedName, assignment *awsv1alpha1.EIPAssignment) *awsv1alpha1.EIPAssignment {
+	if binding.EIPRef.Namespace != key.Namespace || binding.EIPRef.Name != key.Name {
+		return nil
+	}
+	return assignment
+}
+
+// getQueue returns the associations referencing the given EIP that have an
+// assignment, are not being deleted and are permitted to reference it,
+// ordered by precedence: higher priority first, then older associations
+// first.
+func (r *EIPAssociationReconciler) getQueue(ctx context.Context, eip *awsv1alpha1.EIP) ([]awsv1alpha1.EIPAssociation, error) {
+	var eipAssociations awsv1alpha1.EIPAssociationList
+	if err := r.List(ctx, &eipAssociations,
+		client.MatchingFields{eipRefField: eip.Namespace + "/" + eip.Name},
+	); err != nil {
+		return nil, err
+	}
+
+	var grants awsv1alpha1.EIPReferenceGrantList
+	if err := r.List(ctx, &grants, client.InNamespace(eip.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var queue []awsv1alpha1.EIPAssociation
+	for _, eipAssociation := range eipAssociations.Items {
+		if !eipAssociation.ObjectMeta.DeletionTimestamp.IsZero() || eipAssociation.Spec.Assignment == nil {
+			continue
+		}
+		if eipAssociation.Namespace != eip.Namespace && !isGranted(grants.Items, eipAssociation.Namespace, eip.Name) {
+			continue
+		}
+		queue = append(queue, eipAssociation)
+	}
+	sortEIPAssociations(queue)
+	return queue, nil
+}
+
+// getEIPKey returns the namespace and name of the EIP referenced by an
+// association.
+func getEIPKey(eipAssociation *awsv1alpha1.EIPAssociation) types.NamespacedName {
+	key := types.NamespacedName{
+		Namespace: eipAssociation.Namespace,
+		Name:      eipAssociation.Spec.EIPName,
+	}
+	if ref := eipAssociation.Spec.EIPRef; ref != nil {
+		if ref.Name != "" {
+			key.Name = ref.Name
+		}
+		if ref.Namespace != "" {
+			key.Namespace = ref.Namespace
+		}
+	}
+	return key
+}
+
+// getAssignment returns the assignment of an association as it is set on the
+// EIP. Pods and ENIs are always looked up in the namespace of the
+// association.
+func getAssignment(eipAssociation *awsv1alpha1.EIPAssociation, eipNamespace string) *awsv1alpha1.EIPAssignment {
+	if eipAssociation.Spec.Assignment == nil {
+		return nil
+	}
+	assignment := *eipAssociation.Spec.Assignment
+	assignment.Namespace = ""
+	if eipAssociation.Namespace != eipNamespace {
+		assignment.Namespace = eipAssociation.Namespace
+	}
+	return &assignment
+}
+
+// isPermitted checks whether an association may reference the EIP with the
+// given key, i.e. whether the EIP is in the same namespace or an
+// EIPReferenceGrant in the namespace of the EIP permits it.
+func (r *EIPAssociationReconciler) isPermitted(ctx context.Context, eipAssociation *awsv1alpha1.EIPAssociation, key types.NamespacedName) (bool, error) {
+	if key.Namespace == eipAssociation.Namespace {
+		return true, nil
+	}
+
+	var grants awsv1alpha1.EIPReferenceGrantList
+	if err := r.List(ctx, &grants, client.InNamespace(key.Namespace)); err != nil {
+		return false, err
+	}
+	return isGranted(grants.Items, eipAssociation.Namespace, key.Name), nil
+}
+
+// isGranted checks whether any of the grants permits associations in the
+// given namespace to reference the EIP with the given name.
+func isGranted(grants []awsv1alpha1.EIPReferenceGrant, namespace, eipName string) bool {
+	for _, grant := range grants {
+		if len(grant.Spec.EIPNames) > 0 && !containsString(grant.Spec.EIPNames, eipName) {
+			continue
+		}
+		for _, from := range grant.Spec.From {
+			if from.Namespace == namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sortEIPAssociations(eipAssociations []awsv1alpha1.EIPAssociation) {
+	sort.SliceStable(eipAssociations, func(i, j int) bool {
+		a, b := &eipAssociations[i], &eipAssociations[j]
+		if a.Spec.Priority != b.Spec.Priority {
+			return a.Spec.Priority > b.Spec.Priority
+		}
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+		return a.Name < b.Name
+	})
+}
+
+// unboundPhase returns the phase of an association that is not bound (anymore).
+// Associations that were bound are considered lost.
+func (r *EIPAssociationReconciler) unboundPhase(eipAssociation *awsv1alpha1.EIPAssociation, phase awsv1alpha1.EIPAssociationPhase) awsv1alpha1.EIPAssociationPhase {
+	if eipAssociation.Status.Phase == awsv1alpha1.EIPAssociationPhaseBound || eipAssociation.Status.Phase == awsv1alpha1.EIPAssociationPhaseLost {
+		return awsv1alpha1.EIPAssociationPhaseLost
+	}
+	return phase
+}
+
+// setPhase updates the phase and the Bound condition of an association, if
+// they changed.
+func (r *EIPAssociationReconciler) setPhase(ctx context.Context, eipAssociation *awsv1alpha1.EIPAssociation, phase awsv1alpha1.EIPAssociationPhase, reason, message string) error {
+	status := metav1.ConditionFalse
+	if phase == awsv1alpha1.EIPAssociationPhaseBound {
+		status = metav1.ConditionTrue
+	}
+
+	existing := meta.FindStatusCondition(eipAssociation.Status.Conditions, awsv1alpha1.EIPAssociationConditionBound)
+	if eipAssociation.Status.Phase == phase && existing != nil && existing.Status == status && existing.Reason == reason &&
+		existing.Message == message && existing.ObservedGeneration == eipAssociation.Generation {
+		return nil
+	}
+
+	eipAssociation.Status.Phase = phase
+	meta.SetStatusCondition(&eipAssociation.Status.Conditions, metav1.Condition{
+		Type:               awsv1alpha1.EIPAssociationConditionBound,
+		Status:             status,
+		ObservedGeneration: eipAssociation.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return r.Update(ctx, eipAssociation)
+}
+
+// isSameAssignment compares two assignments. The private IP address is
+// ignored for assignments to pods, nodes and ENIs, as the EIP controller fills
+// it in when assigning.
+func isSameAssignment(a, b *awsv1alpha1.EIPAssignment) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.PodName != b.PodName || a.NodeName != b.NodeName || a.ENI != b.ENI || a.ENIPrivateIPAddressIndex != b.ENIPrivateIPAddressIndex || a.Namespace != b.Namespace {
+		return false
+	}
+	return a.PodName != "" || a.NodeName != "" || a.ENI != "" || a.PrivateIPAddress == b.PrivateIPAddress
+}
+
+// findAssociationsForEIP maps an EIP to the associations referencing it.
+func (r *EIPAssociationReconciler) findAssociationsForEIP(obj client.Object) []reconcile.Request {
+	return r.findAssociationsReferencing(obj.GetNamespace(), obj.GetName())
+}
+
+// findAssociationsForAssociation maps an association to all associations
+// referencing the same EIP, so that the next one in the queue can take over
+// when an association is deleted.
+func (r *EIPAssociationReconciler) findAssociationsForAssociation(obj client.Object) []reconcile.Request {
+	key := getEIPKey(obj.(*awsv1alpha1.EIPAssociation))
+	return r.findAssociationsReferencing(key.Namespace, key.Name)
+}
+
+// findAssociationsForGrant maps an EIPReferenceGrant to the associations
+// referencing EIPs in its namespace from other namespaces.
+func (r *EIPAssociationReconciler) findAssociationsForGrant(obj client.Object) []reconcile.Request {
+	var eipAssociations awsv1alpha1.EIPAssociationList
+	if err := r.List(context.Background(), &eipAssociations); err != nil {
+		r.Log.Error(err, "unable to list EIP associations")
+		return nil
+	}
+
+	var filtered []awsv1alpha1.EIPAssociation
+	for _, eipAssociation := range eipAssociations.Items {
+		if eipAssociation.Namespace != obj.GetNamespace() && getEIPKey(&eipAssociation).Namespace == obj.GetNamespace() {
+			filtered = append(filtered, eipAssociation)
+		}
+	}
+	return toRequests(filtered)
+}
+
+func (r *EIPAssociationReconciler) findAssociationsReferencing(namespace, eipName string) []reconcile.Request {
+	var eipAssociations awsv1alpha1.EIPAssociationList
+	if err := r.List(context.Background(), &eipAssociations,
+		client.MatchingFields{eipRefField: namespace + "/" + eipName},
+	); err != nil {
+		r.Log.Error(err, "unable to list EIP associations", "eip", eipName)
+		return nil
+	}
+	return toRequests(eipAssociations.Items)
+}
+
+func toRequests(eipAssociations []awsv1alpha1.EIPAssociation) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, eipAssociation := range eipAssociations {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: eipAssociation.Namespace,
+			Name:      eipAssociation.Name,
+		}})
+	}
+	return requests
+}
+
+// isBindable returns whether an unassigned EIP can be bound by an
+// association: if it is allocated, or if it is being allocated in the network
+// border group of its assignment target, which needs the assignment first.
+func isBindable(eip *awsv1alpha1.EIP) bool {
+	switch eip.Status.State {
+	case awsv1alpha1.EIPStateAllocated:
+		return true
+	case awsv1alpha1.EIPStateAllocating:
+		return eip.Spec.NetworkBorderGroup == awsv1alpha1.NetworkBorderGroupAuto
+	}
+	return false
+}
+
 func (r *EIPAssociationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &awsv1alpha1.EIPAssociation{}, eipRefField, func(obj client.Object) []string {
+		return []string{getEIPKey(obj.(*awsv1alpha1.EIPAssociation)).String()}
+	}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&awsv1alpha1.EIPAssociation{}).
+		Watches(&source.Kind{Type: &awsv1alpha1.EIP{}}, handler.EnqueueRequestsFromMapFunc(r.findAssociationsForEIP)).
+		Watches(&source.Kind{Type: &awsv1alpha1.EIPAssociation{}}, handler.EnqueueRequestsFromMapFunc(r.findAssociationsForAssociation)).
+		Watches(&source.Kind{Type: &awsv1alpha1.EIPReferenceGrant{}}, handler.EnqueueRequestsFromMapFunc(r.findAssociationsForGrant)).
 		Complete(r)
 }
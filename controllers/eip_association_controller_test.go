@@ -0,0 +1,235 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+func TestSortEIPAssociations(t *testing.T) {
+	now := time.Now()
+	association := func(name string, priority int32, age time.Duration) awsv1alpha1.EIPAssociation {
+		return awsv1alpha1.EIPAssociation{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Spec:       awsv1alpha1.EIPAssociationSpec{Priority: priority},
+		}
+	}
+
+	queue := []awsv1alpha1.EIPAssociation{
+		association("new", 0, time.Minute),
+		association("b", 0, time.Hour),
+		association("high", 10, 0),
+		association("a", 0, time.Hour),
+		association("low", -1, 2*time.Hour),
+	}
+	sortEIPAssociations(queue)
+
+	want := []string{"high", "a", "b", "new", "low"}
+	for i, name := range want {
+		if queue[i].Name != name {
+			t.Errorf("queue[%d] = %s, want %s", i, queue[i].Name, name)
+		}
+	}
+}
+
+func TestIsSameAssignment(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *awsv1alpha1.EIPAssignment
+		want bool
+	}{
+		{"both nil", nil, nil, true},
+		{"one nil", &awsv1alpha1.EIPAssignment{PodName: "pod"}, nil, false},
+		{"same pod", &awsv1alpha1.EIPAssignment{PodName: "pod"}, &awsv1alpha1.EIPAssignment{PodName: "pod"}, true},
+		{"pod with resolved IP", &awsv1alpha1.EIPAssignment{PodName: "pod", PrivateIPAddress: "10.0.0.1"}, &awsv1alpha1.EIPAssignment{PodName: "pod"}, true},
+		{"different pod", &awsv1alpha1.EIPAssignment{PodName: "pod"}, &awsv1alpha1.EIPAssignment{PodName: "other"}, false},
+		{"pod and ENI", &awsv1alpha1.EIPAssignment{PodName: "pod"}, &awsv1alpha1.EIPAssignment{ENI: "pod"}, false},
+		{"different ENI index", &awsv1alpha1.EIPAssignment{ENI: "eni"}, &awsv1alpha1.EIPAssignment{ENI: "eni", ENIPrivateIPAddressIndex: 1}, false},
+		{"different IP", &awsv1alpha1.EIPAssignment{PrivateIPAddress: "10.0.0.1"}, &awsv1alpha1.EIPAssignment{PrivateIPAddress: "10.0.0.2"}, false},
+		{"different namespace", &awsv1alpha1.EIPAssignment{PodName: "pod", Namespace: "app"}, &awsv1alpha1.EIPAssignment{PodName: "pod"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isSameAssignment(test.a, test.b); got != test.want {
+				t.Errorf("isSameAssignment() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetEIPKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		eipName string
+		ref     *awsv1alpha1.EIPReference
+		want    string
+	}{
+		{"eipName", "eip", nil, "app/eip"},
+		{"ref", "", &awsv1alpha1.EIPReference{Name: "eip", Namespace: "public-ips"}, "public-ips/eip"},
+		{"eipName with ref namespace", "eip", &awsv1alpha1.EIPReference{Namespace: "public-ips"}, "public-ips/eip"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eipAssociation := &awsv1alpha1.EIPAssociation{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "app"},
+				Spec:       awsv1alpha1.EIPAssociationSpec{EIPName: test.eipName, EIPRef: test.ref},
+			}
+			if got := getEIPKey(eipAssociation).String(); got != test.want {
+				t.Errorf("getEIPKey() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsGranted(t *testing.T) {
+	grants := []awsv1alpha1.EIPReferenceGrant{
+		{Spec: awsv1alpha1.EIPReferenceGrantSpec{
+			From: []awsv1alpha1.EIPReferenceGrantFrom{{Namespace: "team-a"}},
+		}},
+		{Spec: awsv1alpha1.EIPReferenceGrantSpec{
+			From:     []awsv1alpha1.EIPReferenceGrantFrom{{Namespace: "team-b"}},
+			EIPNames: []string{"eip-b"},
+		}},
+	}
+
+	tests := []struct {
+		namespace, eipName string
+		want               bool
+	}{
+		{"team-a", "eip-a", true},
+		{"team-a", "eip-b", true},
+		{"team-b", "eip-b", true},
+		{"team-b", "eip-a", false},
+		{"team-c", "eip-a", false},
+	}
+
+	for _, test := range tests {
+		if got := isGranted(grants, test.namespace, test.eipName); got != test.want {
+			t.Errorf("isGranted(%s, %s) = %v, want %v", test.namespace, test.eipName, got, test.want)
+		}
+	}
+}
+
+func TestIsAssignmentPermitted(t *testing.T) {
+	grants := []awsv1alpha1.EIPReferenceGrant{
+		{Spec: awsv1alpha1.EIPReferenceGrantSpec{
+			From: []awsv1alpha1.EIPReferenceGrantFrom{{Namespace: "team-a"}},
+		}},
+	}
+	association := func(namespace, eipName, podName string) awsv1alpha1.EIPAssociation {
+		return awsv1alpha1.EIPAssociation{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "association"},
+			Spec: awsv1alpha1.EIPAssociationSpec{
+				EIPRef:     &awsv1alpha1.EIPReference{Namespace: "shared", Name: eipName},
+				Assignment: &awsv1alpha1.EIPAssignment{PodName: podName},
+			},
+		}
+	}
+	deleted := association("team-a", "eip", "pod")
+	deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	tests := []struct {
+		name         string
+		namespace    string
+		associations []awsv1alpha1.EIPAssociation
+		want         bool
+	}{
+		{"requested by association", "team-a", []awsv1alpha1.EIPAssociation{association("team-a", "eip", "pod")}, true},
+		{"no association", "team-a", nil, false},
+		{"association for other pod", "team-a", []awsv1alpha1.EIPAssociation{association("team-a", "eip", "other-pod")}, false},
+		{"association for other EIP", "team-a", []awsv1alpha1.EIPAssociation{association("team-a", "other-eip", "pod")}, false},
+		{"association being deleted", "team-a", []awsv1alpha1.EIPAssociation{deleted}, false},
+		{"not granted", "team-b", []awsv1alpha1.EIPAssociation{association("team-b", "eip", "pod")}, false},
+	}
+
+	for _, test := range tests {
+		eip := &awsv1alpha1.EIP{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "eip"},
+			Spec: awsv1alpha1.EIPSpec{
+				Assignment: &awsv1alpha1.EIPAssignment{PodName: "pod", Namespace: test.namespace},
+			},
+		}
+		if got := isAssignmentPermitted(eip, grants, test.associations); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestIsBindable(t *testing.T) {
+	tests := []struct {
+		name               string
+		state              awsv1alpha1.EIPState
+		networkBorderGroup string
+		want               bool
+	}{
+		{"allocated", awsv1alpha1.EIPStateAllocated, "", true},
+		{"allocating", awsv1alpha1.EIPStateAllocating, "", false},
+		{"allocating in network border group of assignment", awsv1alpha1.EIPStateAllocating, awsv1alpha1.NetworkBorderGroupAuto, true},
+		{"allocating in given network border group", awsv1alpha1.EIPStateAllocating, "us-west-2-lax-1", false},
+		{"assigned", awsv1alpha1.EIPStateAssigned, awsv1alpha1.NetworkBorderGroupAuto, false},
+	}
+
+	for _, test := range tests {
+		eip := &awsv1alpha1.EIP{
+			Spec:   awsv1alpha1.EIPSpec{NetworkBorderGroup: test.networkBorderGroup},
+			Status: awsv1alpha1.EIPStatus{State: test.state},
+		}
+		if got := isBindable(eip); got != test.want {
+			t.Errorf("%s: got %t, want %t", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFollowBinding(t *testing.T) {
+	binding := &awsv1alpha1.EIPAssociationBinding{
+		EIPRef:     awsv1alpha1.EIPReference{Namespace: "app", Name: "eip"},
+		Assignment: &awsv1alpha1.EIPAssignment{PodName: "pod-0", PrivateIPAddress: "10.0.0.1"},
+	}
+	eip := types.NamespacedName{Namespace: "app", Name: "eip"}
+
+	tests := []struct {
+		name        string
+		key         types.NamespacedName
+		assignment  *awsv1alpha1.EIPAssignment
+		wantCurrent bool
+		wantRebind  *awsv1alpha1.EIPAssignment
+	}{
+		{"unchanged", eip, &awsv1alpha1.EIPAssignment{PodName: "pod-0"}, true, &awsv1alpha1.EIPAssignment{PodName: "pod-0"}},
+		{"assignment edited", eip, &awsv1alpha1.EIPAssignment{PodName: "pod-1"}, false, &awsv1alpha1.EIPAssignment{PodName: "pod-1"}},
+		{"assignment removed", eip, nil, false, nil},
+		{"EIP renamed", types.NamespacedName{Namespace: "app", Name: "other"}, &awsv1alpha1.EIPAssignment{PodName: "pod-0"}, false, nil},
+		{"EIP in other namespace", types.NamespacedName{Namespace: "shared", Name: "eip"}, &awsv1alpha1.EIPAssignment{PodName: "pod-0"}, false, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isBindingCurrent(binding, test.key, test.assignment); got != test.wantCurrent {
+				t.Errorf("isBindingCurrent() = %t, want %t", got, test.wantCurrent)
+			}
+			if got := getRebindAssignment(binding, test.key, test.assignment); !isSameAssignment(got, test.wantRebind) {
+				t.Errorf("getRebindAssignment() = %+v, want %+v", got, test.wantRebind)
+			}
+		})
+	}
+}
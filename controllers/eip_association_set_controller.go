@@ -0,0 +1,313 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+const (
+	eipAssociationSetLabel = "aws.k8s.logmein.com/eip-association-set"
+	podUIDAnnotation       = "aws.k8s.logmein.com/pod-uid"
+	ordinalPlaceholder     = "{ordinal}"
+	// eipOrdinalLabel holds the ordinal of the pod an EIP selected by an
+	// EIPAssociationSet is for.
+	eipOrdinalLabel = "aws.k8s.logmein.com/ordinal"
+
+	// pods are not watched to avoid caching all pods of the cluster, so
+	// EIPAssociationSets are polled instead
+	eipAssociationSetPollInterval = 15 * time.Second
+	// eipAssociationTerminatingRequeueInterval is how soon associations
+	// are recreated while their predecessors are still being deleted.
+	eipAssociationTerminatingRequeueInterval = 2 * time.Second
+)
+
+// EIPAssociationSetReconciler reconciles a EIPAssociationSet object
+type EIPAssociationSetReconciler struct {
+	client.Client
+	NonCachingClient client.Client
+	Log              logr.Logger
+}
+
+// +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=eipassociationsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+
+func (r *EIPAssociationSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("eipAssociationSet", req.NamespacedName)
+
+	var set awsv1alpha1.EIPAssociationSet
+	if err := r.Get(ctx, req.NamespacedName, &set); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !set.ObjectMeta.DeletionTimestamp.IsZero() {
+		// generated EIPAssociations are garbage collected
+		return ctrl.Result{}, nil
+	}
+
+	desired, err := r.getDesiredAssociations(ctx, &set)
+	if err != nil {
+		if updateErr := r.setStatus(ctx, &set, set.Status.Mappings, err.Error()); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, err
+	}
+
+	var existing awsv1alpha1.EIPAssociationList
+	if err := r.List(ctx, &existing, client.InNamespace(set.Namespace), client.MatchingLabels{eipAssociationSetLabel: set.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// names of associations that are still being deleted, which can only be
+	// recreated once they are gone
+	terminating := make(map[string]bool)
+	for i := range existing.Items {
+		eipAssociation := &existing.Items[i]
+		if !metav1.IsControlledBy(eipAssociation, &set) {
+			continue
+		}
+		if !eipAssociation.ObjectMeta.DeletionTimestamp.IsZero() {
+			terminating[eipAssociation.Name] = true
+			continue
+		}
+
+		// recreate associations of restarted pods, as their IPs changed
+		if d, ok := desired[eipAssociation.Name]; ok &&
+			d.Spec.EIPName == eipAssociation.Spec.EIPName &&
+			d.Spec.Priority == eipAssociation.Spec.Priority &&
+			d.Annotations[podUIDAnnotation] == eipAssociation.Annotations[podUIDAnnotation] {
+			delete(desired, eipAssociation.Name)
+			continue
+		}
+
+		log.Info("deleting EIP association", "eipAssociation", eipAssociation.Name)
+		if err := r.Delete(ctx, eipAssociation); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		terminating[eipAssociation.Name] = true
+	}
+
+	requeueAfter := eipAssociationSetPollInterval
+	for _, eipAssociation := range desired {
+		if terminating[eipAssociation.Name] {
+			log.Info("waiting for EIP association to be deleted before recreating it", "eipAssociation", eipAssociation.Name)
+			requeueAfter = eipAssociationTerminatingRequeueInterval
+			continue
+		}
+		log.Info("creating EIP association", "eipAssociation", eipAssociation.Name, "eip", eipAssociation.Spec.EIPName, "pod", eipAssociation.Spec.Assignment.PodName)
+		if err := r.Create(ctx, eipAssociation); err != nil {
+			if !apierrors.IsAlreadyExists(err) {
+				return ctrl.Result{}, err
+			}
+			// still being deleted but not yet seen as such by the cache, or not
+			// generated by this set
+			log.Info("EIP association still exists, retrying", "eipAssociation", eipAssociation.Name)
+			requeueAfter = eipAssociationTerminatingRequeueInterval
+		}
+	}
+
+	mappings, err := r.getMappings(ctx, &set)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, r.setStatus(ctx, &set, mappings, "")
+}
+
+// getDesiredAssociations returns the EIPAssociations to generate, by name.
+func (r *EIPAssociationSetReconciler) getDesiredAssociations(ctx context.Context, set *awsv1alpha1.EIPAssociationSet) (map[string]*awsv1alpha1.EIPAssociation, error) {
+	if (set.Spec.EIPNameTemplate == "") == (set.Spec.EIPSelector == nil) {
+		return nil, errors.New("exactly one of eipNameTemplate or eipSelector needs to be given")
+	}
+
+	podSelector, err := metav1.LabelSelectorAsSelector(&set.Spec.PodSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	// we use a non-caching client here as otherwise we would need to cache all pods in the cluster
+	var pods corev1.PodList
+	if err := r.NonCachingClient.List(ctx, &pods, client.InNamespace(set.Namespace), client.MatchingLabelsSelector{Selector: podSelector}); err != nil {
+		return nil, err
+	}
+
+	var eipNames map[int]string
+	if set.Spec.EIPSelector != nil {
+		eipSelector, err := metav1.LabelSelectorAsSelector(set.Spec.EIPSelector)
+		if err != nil {
+			return nil, err
+		}
+
+		var eips awsv1alpha1.EIPList
+		if err := r.List(ctx, &eips, client.InNamespace(set.Namespace), client.MatchingLabelsSelector{Selector: eipSelector}); err != nil {
+			return nil, err
+		}
+		if eipNames, err = getEIPsByOrdinal(eips.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	desired := make(map[string]*awsv1alpha1.EIPAssociation)
+	for _, pod := range pods.Items {
+		if !pod.ObjectMeta.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		ordinal, ok := getPodOrdinal(&pod)
+		if !ok {
+			continue
+		}
+
+		var eipName string
+		if set.Spec.EIPSelector != nil {
+			if eipName, ok = eipNames[ordinal]; !ok {
+				continue
+			}
+		} else {
+			eipName = strings.ReplaceAll(set.Spec.EIPNameTemplate, ordinalPlaceholder, strconv.Itoa(ordinal))
+		}
+
+		eipAssociation := &awsv1alpha1.EIPAssociation{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   set.Namespace,
+				Name:        set.Name + "-" + pod.Name,
+				Labels:      map[string]string{eipAssociationSetLabel: set.Name},
+				Annotations: map[string]string{podUIDAnnotation: string(pod.UID)},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(set, awsv1alpha1.GroupVersion.WithKind("EIPAssociationSet")),
+				},
+			},
+			Spec: awsv1alpha1.EIPAssociationSpec{
+				EIPName:  eipName,
+				Priority: set.Spec.Priority,
+				Assignment: &awsv1alpha1.EIPAssignment{
+					PodName: pod.Name,
+				},
+			},
+		}
+		desired[eipAssociation.Name] = eipAssociation
+	}
+
+	return desired, nil
+}
+
+// getMappings returns the pods that EIPs are associated with.
+func (r *EIPAssociationSetReconciler) getMappings(ctx context.Context, set *awsv1alpha1.EIPAssociationSet) ([]awsv1alpha1.EIPAssociationSetMapping, error) {
+	var eipAssociations awsv1alpha1.EIPAssociationList
+	if err := r.List(ctx, &eipAssociations, client.InNamespace(set.Namespace), client.MatchingLabels{eipAssociationSetLabel: set.Name}); err != nil {
+		return nil, err
+	}
+
+	var mappings []awsv1alpha1.EIPAssociationSetMapping
+	for _, eipAssociation := range eipAssociations.Items {
+		if eipAssociation.Status.Phase != awsv1alpha1.EIPAssociationPhaseBound || eipAssociation.Spec.Assignment == nil {
+			continue
+		}
+		mappings = append(mappings, awsv1alpha1.EIPAssociationSetMapping{
+			PodName:            eipAssociation.Spec.Assignment.PodName,
+			EIPName:            eipAssociation.Spec.EIPName,
+			EIPAssociationName: eipAssociation.Name,
+		})
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].PodName < mappings[j].PodName
+	})
+	return mappings, nil
+}
+
+func (r *EIPAssociationSetReconciler) setStatus(ctx context.Context, set *awsv1alpha1.EIPAssociationSet, mappings []awsv1alpha1.EIPAssociationSetMapping, errorMessage string) error {
+	if set.Status.Error == errorMessage && len(set.Status.Mappings) == len(mappings) {
+		changed := false
+		for i := range mappings {
+			if set.Status.Mappings[i] != mappings[i] {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return nil
+		}
+	}
+
+	set.Status.Mappings = mappings
+	set.Status.Error = errorMessage
+	return r.Update(ctx, set)
+}
+
+// getPodOrdinal returns the ordinal of a StatefulSet pod, which is the number
+// after the name of the StatefulSet in the name of the pod. Pods not
+// controlled by a StatefulSet have no ordinal.
+func getPodOrdinal(pod *corev1.Pod) (int, bool) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "StatefulSet" || !strings.HasPrefix(owner.APIVersion, "apps/") {
+		return 0, false
+	}
+	suffix := strings.TrimPrefix(pod.Name, owner.Name+"-")
+	if suffix == pod.Name || suffix == "" || strings.TrimLeft(suffix, "0123456789") != "" {
+		return 0, false
+	}
+	ordinal, err := strconv.Atoi(suffix)
+	if err != nil {
+		return 0, false
+	}
+	return ordinal, true
+}
+
+// getEIPsByOrdinal returns the names of EIPs by the ordinal in their
+// eipOrdinalLabel. EIPs without a valid ordinal are ignored.
+func getEIPsByOrdinal(eips []awsv1alpha1.EIP) (map[int]string, error) {
+	names := make(map[int]string)
+	for _, eip := range eips {
+		value, ok := eip.Labels[eipOrdinalLabel]
+		if !ok {
+			continue
+		}
+		ordinal, err := strconv.Atoi(value)
+		if err != nil || ordinal < 0 {
+			continue
+		}
+		if other, ok := names[ordinal]; ok {
+			first, second := other, eip.Name
+			if second < first {
+				first, second = second, first
+			}
+			return nil, fmt.Errorf("EIPs %s and %s both have ordinal %d", first, second, ordinal)
+		}
+		names[ordinal] = eip.Name
+	}
+	return names, nil
+}
+
+func (r *EIPAssociationSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&awsv1alpha1.EIPAssociationSet{}).
+		Owns(&awsv1alpha1.EIPAssociation{}).
+		Complete(r)
+}
@@ -0,0 +1,167 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+func statefulSetPod(name, ownerKind, ownerName string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      name,
+		UID:       types.UID(name),
+		Labels:    map[string]string{"app": "web"},
+	}}
+	if ownerKind != "" {
+		apiVersion := "apps/v1"
+		if ownerKind == "Job" {
+			apiVersion = "batch/v1"
+		}
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: apiVersion, Kind: ownerKind, Name: ownerName, Controller: &controller}}
+	}
+	return pod
+}
+
+func TestGetPodOrdinal(t *testing.T) {
+	tests := []struct {
+		name        string
+		pod         *corev1.Pod
+		wantOrdinal int
+		wantOK      bool
+	}{
+		{"StatefulSet pod", statefulSetPod("web-0", "StatefulSet", "web"), 0, true},
+		{"StatefulSet pod with dashed name", statefulSetPod("my-web-12", "StatefulSet", "my-web"), 12, true},
+		{"ReplicaSet pod with numeric suffix", statefulSetPod("web-6d4f8-24567", "ReplicaSet", "web-6d4f8"), 0, false},
+		{"pod without controller", statefulSetPod("web-1", "", ""), 0, false},
+		{"other kind named StatefulSet", statefulSetPod("web-1", "Job", "web"), 0, false},
+		{"name not matching the StatefulSet", statefulSetPod("other-1", "StatefulSet", "web"), 0, false},
+		{"non-numeric suffix", statefulSetPod("web-a", "StatefulSet", "web"), 0, false},
+		{"signed suffix", statefulSetPod("web-+1", "StatefulSet", "web"), 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ordinal, ok := getPodOrdinal(test.pod)
+			if ordinal != test.wantOrdinal || ok != test.wantOK {
+				t.Errorf("getPodOrdinal() = %d, %t, want %d, %t", ordinal, ok, test.wantOrdinal, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestGetDesiredAssociations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := awsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	eip := func(name, ordinal string) *awsv1alpha1.EIP {
+		labels := map[string]string{"pool": "egress"}
+		if ordinal != "" {
+			labels[eipOrdinalLabel] = ordinal
+		}
+		return &awsv1alpha1.EIP{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels}}
+	}
+	pods := []client.Object{
+		statefulSetPod("web-0", "StatefulSet", "web"),
+		statefulSetPod("web-1", "StatefulSet", "web"),
+		statefulSetPod("web-2", "StatefulSet", "web"),
+		statefulSetPod("web-6d4f8-24567", "ReplicaSet", "web-6d4f8"),
+	}
+	eipSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "egress"}}
+
+	tests := []struct {
+		name    string
+		spec    awsv1alpha1.EIPAssociationSetSpec
+		eips    []client.Object
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "name template",
+			spec: awsv1alpha1.EIPAssociationSetSpec{EIPNameTemplate: "egress-{ordinal}"},
+			want: map[string]string{"set-web-0": "egress-0", "set-web-1": "egress-1", "set-web-2": "egress-2"},
+		},
+		{
+			name: "selector by ordinal label",
+			spec: awsv1alpha1.EIPAssociationSetSpec{EIPSelector: eipSelector},
+			// adding "a" or removing "b" does not move the other EIPs
+			eips: []client.Object{eip("a", ""), eip("c", "0"), eip("b", "2"), eip("d", "invalid")},
+			want: map[string]string{"set-web-0": "c", "set-web-2": "b"},
+		},
+		{
+			name:    "selector with duplicate ordinal",
+			spec:    awsv1alpha1.EIPAssociationSetSpec{EIPSelector: eipSelector},
+			eips:    []client.Object{eip("a", "0"), eip("b", "0")},
+			wantErr: true,
+		},
+		{
+			name:    "template and selector",
+			spec:    awsv1alpha1.EIPAssociationSetSpec{EIPNameTemplate: "egress-{ordinal}", EIPSelector: eipSelector},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(test.eips, pods...)...).Build()
+			r := &EIPAssociationSetReconciler{Client: k8sClient, NonCachingClient: k8sClient, Log: logr.Discard()}
+			set := &awsv1alpha1.EIPAssociationSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "set"},
+				Spec:       test.spec,
+			}
+			set.Spec.PodSelector = metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+
+			desired, err := r.getDesiredAssociations(context.Background(), set)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+			if len(desired) != len(test.want) {
+				t.Errorf("got %d associations, want %d", len(desired), len(test.want))
+			}
+			for name, eipName := range test.want {
+				eipAssociation, ok := desired[name]
+				if !ok {
+					t.Errorf("association %s missing", name)
+					continue
+				}
+				if eipAssociation.Spec.EIPName != eipName {
+					t.Errorf("association %s: got EIP %s, want %s", name, eipAssociation.Spec.EIPName, eipName)
+				}
+				if eipAssociation.Annotations[podUIDAnnotation] != eipAssociation.Spec.Assignment.PodName {
+					t.Errorf("association %s: got pod UID %s", name, eipAssociation.Annotations[podUIDAnnotation])
+				}
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/go-logr/logr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -28,126 +29,202 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
 
 	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
 )
 
-// EIPReconciler reconciles a EIP object
+// EIPReconciler reconciles a EIP object, or a ClusterEIP object if
+// ClusterScoped is set.
 type EIPReconciler struct {
 	client.Client
 	NonCachingClient client.Client
 	Log              logr.Logger
-	EC2              *ec2.EC2
+	EC2              ec2iface.EC2API
+	EC2Clients       *EC2Clients
+	Route53          route53iface.Route53API
 	Tags             map[string]string
+	ClusterScoped    bool
+
+	// ClusterName is tagged on created resources to mark them as owned by
+	// the cluster, see ownershipTags.
+	ClusterName string
+	// AdoptUntagged allows adopting resources that are not tagged as owned
+	// by any cluster, e.g. created by earlier versions of the operator.
+	AdoptUntagged bool
+	// DryRun skips writes to Kubernetes that follow from planned AWS
+	// calls, e.g. to pods, and records them as planned actions instead.
+	DryRun bool
+
+	// region of EC2, set by withEC2Client
+	region string
+
+	// next pool index per set of pools for the RoundRobin allocation
+	// strategy
+	roundRobin *sync.Map
 }
 
 // +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=eips,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=eips/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=clustereips,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get
 
 func (r *EIPReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	result, err := r.reconcile(ctx, req)
+	err = transientAWSError(err)
+	logPlannedAction(r.Log.WithValues("eip", req.NamespacedName), err)
+	if err := r.updateSyncedCondition(ctx, req.NamespacedName, err); err != nil {
+		return ctrl.Result{}, err
+	}
+	return awsErrorResult(result, err)
+}
+
+func (r *EIPReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("eip", req.NamespacedName)
 
 	var eip awsv1alpha1.EIP
-	if err := r.Get(ctx, req.NamespacedName, &eip); err != nil {
+	if err := r.getEIP(ctx, req.NamespacedName, &eip); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	r, err := r.withEC2Client(ctx, eip.Spec.AWSAccountName, eip.Spec.Region, eip.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	status := &eip.Status
 	spec := &eip.Spec
 
+	if containsString(eip.ObjectMeta.Finalizers, finalizerName) && !isKnownEIPState(status.State) {
+		state := recoverEIPState(&eip)
+		log.Info("recovering unknown state", "state", status.State, "recoveredState", state)
+		forceEIPState(&eip, state)
+		return ctrl.Result{}, r.updateEIP(ctx, &eip)
+	}
+
 	if eip.ObjectMeta.DeletionTimestamp.IsZero() {
 		if !containsString(eip.ObjectMeta.Finalizers, finalizerName) {
 			// add finalizer, set initial state
 			eip.ObjectMeta.Finalizers = append(eip.ObjectMeta.Finalizers, finalizerName)
-			status.State = "allocating"
-			return ctrl.Result{}, r.Update(ctx, &eip)
+			if status.State != "" {
+				// e.g. created with a status, or before the finalizer was added
+				return ctrl.Result{}, r.updateEIP(ctx, &eip)
+			}
+			return ctrl.Result{}, r.setState(ctx, &eip, awsv1alpha1.EIPStateAllocating)
 		}
 
-		if status.State == "allocating" {
+		if status.State == awsv1alpha1.EIPStateAllocating {
 			return ctrl.Result{}, r.allocateEIP(ctx, &eip, log)
 		}
 
-		resp, err := r.EC2.DescribeAddressesWithContext(ctx, &ec2.DescribeAddressesInput{
-			AllocationIds: []*string{aws.String(status.AllocationId)},
-		})
-		if err != nil {
-			if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "InvalidAllocationID.NotFound" {
-				log.Info("allocation ID not found; assuming EIP was released; not doing anything", "allocationId", eip.Status.AllocationId)
+		if status.State == awsv1alpha1.EIPStateLost {
+			if spec.OnExternalRelease == awsv1alpha1.ExternalReleaseReallocate {
+				return ctrl.Result{}, r.setState(ctx, &eip, awsv1alpha1.EIPStateAllocating)
 			}
+			return ctrl.Result{}, nil
+		}
+
+		addr, err := describeAddress(ctx, r.EC2, status.AllocationId)
+		if isNotFound(err) {
+			return ctrl.Result{}, r.handleExternalRelease(ctx, &eip, log)
+		} else if err != nil {
 			return ctrl.Result{}, err
 		}
 
-		addr := resp.Addresses[0]
+		if err := ensureOwnership(ctx, r.EC2, "EIP", status.AllocationId, addr.Tags, &eip, r.ClusterName, r.AdoptUntagged, true, log); err != nil {
+			return ctrl.Result{}, err
+		}
 
 		if err := r.reconcileTags(ctx, &eip, addr.Tags); err != nil {
 			return ctrl.Result{}, err
 		}
 
-		if status.State == "allocated" {
-			if spec.Assignment != nil {
-				if spec.Assignment.PodName != "" || spec.Assignment.ENI != "" || spec.Assignment.PrivateIPAddress != "" {
-					status.State = "assigning"
-					return ctrl.Result{}, r.Update(ctx, &eip)
-				}
-			}
+		reverseDNSChanged, requeueAfter := r.reconcileReverseDNS(ctx, &eip, log)
+		if reverseDNSChanged {
+			return ctrl.Result{RequeueAfter: requeueAfter}, r.updateEIP(ctx, &eip)
 		}
+		result := ctrl.Result{RequeueAfter: requeueAfter}
 
-		if status.State == "assigned" {
-			changed := false
+		if dnsChanged, err := r.reconcileDNS(ctx, &eip, log); err != nil {
+			return result, err
+		} else if dnsChanged {
+			return result, r.updateEIP(ctx, &eip)
+		}
+
+		switch status.State {
+		case awsv1alpha1.EIPStateAllocated:
+			if spec.Assignment != nil && hasAssignmentTarget(spec.Assignment) {
+				return result, r.setState(ctx, &eip, awsv1alpha1.EIPStateAssigning)
+			}
+
+		case awsv1alpha1.EIPStateAssigned:
 			if spec.Assignment == nil {
 				// assignment was removed
-				status.State = "unassigning"
-				changed = true
-			} else if *(spec.Assignment) != *(status.Assignment) || addr.AssociationId == nil {
+				return result, r.setState(ctx, &eip, awsv1alpha1.EIPStateUnassigning)
+			}
+			if status.Assignment == nil || *(spec.Assignment) != *(status.Assignment) || addr.AssociationId == nil {
 				// assignment was changed (in spec or in EC2)
-				status.State = "reassigning"
-				changed = true
+				return result, r.setState(ctx, &eip, awsv1alpha1.EIPStateReassigning)
 			}
 
-			if changed {
-				return ctrl.Result{}, r.Update(ctx, &eip)
+			if status.Assignment.PodName != "" && !status.PodAnnotated {
+				// the pod is annotated once it is assigned
+				if err := r.reconcilePodAnnotations(ctx, &eip, status.Assignment, true); err != nil {
+					return result, err
+				}
+				status.PodAnnotated = true
+				return result, r.updateEIP(ctx, &eip)
 			}
-		}
 
-		if status.State == "assigning" {
+		case awsv1alpha1.EIPStateAssigning:
 			if spec.Assignment == nil {
 				// assignment was removed before EIP was actually assigned
-				status.State = "allocated"
-				return ctrl.Result{}, r.Update(ctx, &eip)
+				return result, r.setState(ctx, &eip, awsv1alpha1.EIPStateAllocated)
+			}
+			if hasAssignmentTarget(spec.Assignment) {
+				return result, r.assignEIP(ctx, &eip, log)
 			}
-		}
 
-		if status.State == "assigning" || status.State == "reassigning" {
-			if spec.Assignment != nil {
-				if spec.Assignment.PodName != "" || spec.Assignment.ENI != "" || spec.Assignment.PrivateIPAddress != "" {
-					return ctrl.Result{}, r.assignEIP(ctx, &eip, log)
-				}
+		case awsv1alpha1.EIPStateReassigning:
+			if spec.Assignment == nil {
+				return result, r.setState(ctx, &eip, awsv1alpha1.EIPStateUnassigning)
+			}
+			if hasAssignmentTarget(spec.Assignment) {
+				return result, r.assignEIP(ctx, &eip, log)
 			}
-		}
 
-		if status.State == "unassigning" {
-			return ctrl.Result{}, r.unassignEIP(ctx, &eip, log)
+		case awsv1alpha1.EIPStateUnassigning:
+			return result, r.unassignEIP(ctx, &eip, log)
 		}
+
+		return result, nil
 	} else {
 		// EIP object is being deleted
 		if containsString(eip.ObjectMeta.Finalizers, finalizerName) {
-			if status.State == "assigned" || status.State == "reassigning" {
-				status.State = "unassigning"
-				return ctrl.Result{}, r.Update(ctx, &eip)
+			if status.State == awsv1alpha1.EIPStateUnassigning || status.State == awsv1alpha1.EIPStateReleasing {
+				if err := r.checkOwnership(ctx, &eip, log); err != nil {
+					return ctrl.Result{}, err
+				}
 			}
 
-			if status.State == "unassigning" {
+			switch status.State {
+			case awsv1alpha1.EIPStateAssigned, awsv1alpha1.EIPStateReassigning:
+				return ctrl.Result{}, r.setState(ctx, &eip, awsv1alpha1.EIPStateUnassigning)
+			case awsv1alpha1.EIPStateAssigning:
+				// not assigned yet
+				return ctrl.Result{}, r.setState(ctx, &eip, awsv1alpha1.EIPStateAllocated)
+			case awsv1alpha1.EIPStateUnassigning:
 				return ctrl.Result{}, r.unassignEIP(ctx, &eip, log)
+			case awsv1alpha1.EIPStateAllocated:
+				return ctrl.Result{}, r.setState(ctx, &eip, awsv1alpha1.EIPStateReleasing)
 			}
 
-			if status.State == "allocated" {
-				status.State = "releasing"
-				return ctrl.Result{}, r.Update(ctx, &eip)
-			}
-
-			if status.State == "releasing" {
+			if status.State == awsv1alpha1.EIPStateReleasing {
+				if err := r.deleteDNSRecord(ctx, &eip); err != nil {
+					return ctrl.Result{}, err
+				}
 				if err := r.releaseEIP(ctx, &eip, log); err != nil {
 					return ctrl.Result{}, err
 				}
@@ -155,40 +232,146 @@ func (r *EIPReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 
 			// remove finalizer, allow k8s to remove the resource
 			eip.ObjectMeta.Finalizers = removeString(eip.ObjectMeta.Finalizers, finalizerName)
-			return ctrl.Result{}, r.Update(ctx, &eip)
+			return ctrl.Result{}, r.updateEIP(ctx, &eip)
 		}
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// updateSyncedCondition records the outcome of a reconciliation in the Synced
+// condition of the EIP.
+func (r *EIPReconciler) updateSyncedCondition(ctx context.Context, key types.NamespacedName, reconcileErr error) error {
+	var eip awsv1alpha1.EIP
+	if err := r.getEIP(ctx, key, &eip); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !setSyncedCondition(&eip.Status.Conditions, eip.Generation, reconcileErr) {
+		return nil
+	}
+	// a conflicting update triggers another reconciliation anyway
+	return ignoreConflict(r.updateEIP(ctx, &eip))
+}
+
+// setState changes the state of an EIP and stores it.
+func (r *EIPReconciler) setState(ctx context.Context, eip *awsv1alpha1.EIP, state awsv1alpha1.EIPState) error {
+	if err := setEIPState(eip, state); err != nil {
+		return err
+	}
+	return r.updateEIP(ctx, eip)
+}
+
+// withEC2Client returns a copy of the reconciler using the EC2 client of the
+// given AWSAccount and region, or the reconciler itself if neither is given.
+func (r *EIPReconciler) withEC2Client(ctx context.Context, accountName, region, namespace string) (*EIPReconciler, error) {
+	if accountName == "" && region == "" {
+		return r, nil
+	}
+	var account *awsv1alpha1.AWSAccount
+	if accountName != "" {
+		var err error
+		if account, err = getAWSAccount(ctx, r.Client, accountName, namespace); err != nil {
+			return nil, err
+		}
+	}
+	copy := *r
+	copy.EC2, copy.region = r.EC2Clients.Get(account, region)
+	return &copy, nil
+}
+
+// getEIP gets an EIP, or a ClusterEIP as an EIP without namespace.
+func (r *EIPReconciler) getEIP(ctx context.Context, key types.NamespacedName, eip *awsv1alpha1.EIP) error {
+	if !r.ClusterScoped {
+		return r.Get(ctx, key, eip)
+	}
+
+	var clusterEIP awsv1alpha1.ClusterEIP
+	if err := r.Get(ctx, key, &clusterEIP); err != nil {
+		return err
+	}
+	eip.ObjectMeta = clusterEIP.ObjectMeta
+	eip.Spec = clusterEIP.Spec
+	eip.Status = clusterEIP.Status
+	return nil
+}
+
+// updateEIP updates an EIP, or the ClusterEIP it was read from.
+func (r *EIPReconciler) updateEIP(ctx context.Context, eip *awsv1alpha1.EIP) error {
+	if !r.ClusterScoped {
+		return r.Update(ctx, eip)
+	}
+
+	clusterEIP := &awsv1alpha1.ClusterEIP{
+		ObjectMeta: eip.ObjectMeta,
+		Spec:       eip.Spec,
+		Status:     eip.Status,
+	}
+	if err := r.Update(ctx, clusterEIP); err != nil {
+		return err
+	}
+	eip.ObjectMeta = clusterEIP.ObjectMeta
+	return nil
+}
+
+// deleteEIP deletes an EIP, or the ClusterEIP it was read from.
+func (r *EIPReconciler) deleteEIP(ctx context.Context, eip *awsv1alpha1.EIP) error {
+	if !r.ClusterScoped {
+		return r.Delete(ctx, eip)
+	}
+	return r.Delete(ctx, &awsv1alpha1.ClusterEIP{ObjectMeta: eip.ObjectMeta})
+}
+
+func hasAssignmentTarget(assignment *awsv1alpha1.EIPAssignment) bool {
+	return assignment.PodName != "" || assignment.ENI != "" || assignment.PrivateIPAddress != "" || assignment.NodeName != ""
+}
+
 func (r *EIPReconciler) allocateEIP(ctx context.Context, eip *awsv1alpha1.EIP, log logr.Logger) error {
+	if err := enforceEIPPolicies(ctx, r.Client, eip); err != nil {
+		return err
+	}
+
 	log.Info("allocating")
 
 	input := &ec2.AllocateAddressInput{
 		Domain: aws.String("vpc"),
 	}
+	if networkBorderGroup := eip.Spec.NetworkBorderGroup; networkBorderGroup != "" {
+		if networkBorderGroup == awsv1alpha1.NetworkBorderGroupAuto {
+			var err error
+			if networkBorderGroup, err = r.detectNetworkBorderGroup(ctx, eip); err != nil {
+				return err
+			}
+		}
+		input.NetworkBorderGroup = aws.String(networkBorderGroup)
+	}
 	if eip.Spec.PublicIPAddress != "" {
 		input.Address = aws.String(eip.Spec.PublicIPAddress)
 	} else if eip.Spec.PublicIPv4Pool != "" {
-		input.PublicIpv4Pool = aws.String(eip.Spec.PublicIPv4Pool)
-	} else if len(eip.Spec.PublicIPv4Pools) > 0 {
-		if resp, err := r.EC2.DescribePublicIpv4PoolsWithContext(ctx, &ec2.DescribePublicIpv4PoolsInput{
-			PoolIds: aws.StringSlice(eip.Spec.PublicIPv4Pools),
-		}); err != nil {
+		if _, err := r.describePublicIPv4Pools(ctx, []string{eip.Spec.PublicIPv4Pool}); err != nil {
 			return err
+		}
+		input.PublicIpv4Pool = aws.String(eip.Spec.PublicIPv4Pool)
+	} else if len(eip.Spec.PublicIPv4Pools) > 0 || eip.Spec.IPAMPoolID != "" {
+		var pools []*ec2.PublicIpv4Pool
+		var err error
+		if len(eip.Spec.PublicIPv4Pools) > 0 {
+			pools, err = r.describePublicIPv4Pools(ctx, eip.Spec.PublicIPv4Pools)
 		} else {
-			var chosenPool *ec2.PublicIpv4Pool
-			for _, pool := range resp.PublicIpv4Pools {
-				if chosenPool == nil || *pool.TotalAvailableAddressCount > *chosenPool.TotalAvailableAddressCount {
-					chosenPool = pool
-				}
-			}
-			if chosenPool == nil {
-				return fmt.Errorf("no public IPv4 pool found")
+			pools, err = r.getIPAMPublicIPv4Pools(ctx, eip.Spec.IPAMPoolID)
+		}
+		if err != nil {
+			return err
+		}
+		chosenPool, err := r.choosePublicIPv4Pool(eip.Spec.PublicIPv4Pools, pools, eip.Spec.AllocationStrategy)
+		if err != nil {
+			return err
+		}
+		if eip.Spec.IPAMPoolID != "" {
+			if err := enforcePublicIPv4PoolPolicies(ctx, r.Client, eip.Namespace, chosenPool); err != nil {
+				return err
 			}
-			input.PublicIpv4Pool = chosenPool.PoolId
 		}
+		input.PublicIpv4Pool = aws.String(chosenPool)
 	}
 
 	tags := ec2.TagSpecification{
@@ -197,31 +380,77 @@ func (r *EIPReconciler) allocateEIP(ctx context.Context, eip *awsv1alpha1.EIP, l
 	}
 	input.TagSpecifications = []*ec2.TagSpecification{&tags}
 
-	if resp, err := r.EC2.AllocateAddressWithContext(ctx, input); err != nil {
-		return err
-	} else {
-		eip.Status.State = "allocated"
-		eip.Status.AllocationId = aws.StringValue(resp.AllocationId)
-		eip.Status.PublicIPAddress = aws.StringValue(resp.PublicIp)
-		r.Log.Info("allocated", "allocationId", eip.Status.AllocationId)
-		if err := r.Update(ctx, eip); err != nil {
+	var resp *ec2.AllocateAddressOutput
+	if eip.Spec.PublicIPAddress == "" && eip.Status.PublicIPAddress != "" {
+		// reallocating after an external release
+		resp, input = r.recoverAddress(ctx, input, eip, log)
+	}
+	if resp == nil {
+		var err error
+		if resp, err = r.EC2.AllocateAddressWithContext(ctx, input); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	if err := setEIPState(eip, awsv1alpha1.EIPStateAllocated); err != nil {
+		return err
+	}
+	eip.Status.AllocationId = aws.StringValue(resp.AllocationId)
+	eip.Status.PublicIPAddress = aws.StringValue(resp.PublicIp)
+	eip.Status.PublicIPv4Pool = aws.StringValue(input.PublicIpv4Pool)
+	eip.Status.CarrierIPAddress = aws.StringValue(resp.CarrierIp)
+	eip.Status.NetworkBorderGroup = aws.StringValue(resp.NetworkBorderGroup)
+	r.Log.Info("allocated", "allocationId", eip.Status.AllocationId, "publicIPv4Pool", eip.Status.PublicIPv4Pool, "networkBorderGroup", eip.Status.NetworkBorderGroup)
+	return r.updateEIP(ctx, eip)
+}
+
+// describePublicIPv4Pools describes the given BYOIP pools, failing if any of
+// them does not exist in the region of the EIP.
+func (r *EIPReconciler) describePublicIPv4Pools(ctx context.Context, poolIDs []string) ([]*ec2.PublicIpv4Pool, error) {
+	resp, err := r.EC2.DescribePublicIpv4PoolsWithContext(ctx, &ec2.DescribePublicIpv4PoolsInput{
+		PoolIds: aws.StringSlice(poolIDs),
+	})
+	if isAWSErrorCode(err, "InvalidPublicIpv4PoolID.NotFound") {
+		return nil, fmt.Errorf("public IPv4 pools %v not found in %s", poolIDs, describeRegion(r.region))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	for _, pool := range resp.PublicIpv4Pools {
+		found[aws.StringValue(pool.PoolId)] = true
+	}
+	for _, poolID := range poolIDs {
+		if !found[poolID] {
+			return nil, fmt.Errorf("public IPv4 pool %s not found in %s", poolID, describeRegion(r.region))
+		}
+	}
+	return resp.PublicIpv4Pools, nil
 }
 
 // combineDefaultAndDefinedTags combines the default tags defined in the controller
-// with the tags defined in the EIP spec. Tags defined in the EIP spec override
-// default tags in case of key conflicts.
+// with the tags defined in the EIP spec and the ownership tags. Tags defined in
+// the EIP spec override default tags in case of key conflicts.
 func (r EIPReconciler) combineDefaultAndDefinedTags(eip *awsv1alpha1.EIP) []*ec2.Tag {
-	var tags []*ec2.Tag
-	tags = convertMapToTags(r.Tags)
+	var specTags map[string]string
 	if eip.Spec.Tags != nil {
-		tags = append(tags, convertMapToTags(*eip.Spec.Tags)...)
+		specTags = *eip.Spec.Tags
+	}
+	return mergeTags(r.Tags, specTags, ownershipTags(r.ClusterName, eip))
+}
+
+// checkOwnership checks that the allocation of the EIP is owned by it before
+// unassigning or releasing it. Allocations that are gone already are left to
+// the callers.
+func (r *EIPReconciler) checkOwnership(ctx context.Context, eip *awsv1alpha1.EIP, log logr.Logger) error {
+	addr, err := describeAddress(ctx, r.EC2, eip.Status.AllocationId)
+	if isNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
 	}
-	return tags
+	return ensureOwnership(ctx, r.EC2, "EIP", eip.Status.AllocationId, addr.Tags, eip, r.ClusterName, r.AdoptUntagged, true, log)
 }
 
 func (r *EIPReconciler) reconcileTags(ctx context.Context, eip *awsv1alpha1.EIP, existingTags []*ec2.Tag) error {
@@ -278,10 +507,22 @@ func (r *EIPReconciler) reconcileTags(ctx context.Context, eip *awsv1alpha1.EIP,
 func (r *EIPReconciler) releaseEIP(ctx context.Context, eip *awsv1alpha1.EIP, log logr.Logger) error {
 	log.Info("releasing")
 
+	if eip.Status.ReverseDNS != nil && eip.Status.ReverseDNS.DomainName != "" {
+		// EIPs with a custom PTR record cannot be released
+		if _, err := r.EC2.ResetAddressAttributeWithContext(ctx, &ec2.ResetAddressAttributeInput{
+			AllocationId: aws.String(eip.Status.AllocationId),
+			Attribute:    aws.String(ec2.AddressAttributeNameDomainName),
+		}); err != nil {
+			if !isAWSErrorCode(err, "InvalidAllocationID.NotFound") {
+				return err
+			}
+		}
+	}
+
 	if _, err := r.EC2.ReleaseAddressWithContext(ctx, &ec2.ReleaseAddressInput{
 		AllocationId: aws.String(eip.Status.AllocationId),
 	}); err != nil {
-		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "InvalidAllocationID.NotFound" {
+		if isAWSErrorCode(err, "InvalidAllocationID.NotFound") {
 			log.Info("allocation ID not found; assuming EIP already released", "allocationId", eip.Status.AllocationId)
 		} else {
 			return err
@@ -305,6 +546,22 @@ func (r *EIPReconciler) getPodPrivateIP(ctx context.Context, namespace, podName
 	return pod.Status.PodIP, nil
 }
 
+func (r *EIPReconciler) getNodePrivateIP(ctx context.Context, nodeName string) (string, error) {
+	node := &corev1.Node{}
+	if err := r.NonCachingClient.Get(ctx, client.ObjectKey{
+		Name: nodeName,
+	}, node); err != nil {
+		return "", err
+	}
+
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			return address.Address, nil
+		}
+	}
+	return "", fmt.Errorf("node %s has no internal IP", nodeName)
+}
+
 func (r *EIPReconciler) findENI(ctx context.Context, privateIP string) (string, error) {
 	if resp, err := r.EC2.DescribeNetworkInterfacesWithContext(ctx, &ec2.DescribeNetworkInterfacesInput{
 		Filters: []*ec2.Filter{
@@ -326,6 +583,54 @@ func (r *EIPReconciler) findENI(ctx context.Context, privateIP string) (string,
 	}
 }
 
+// checkAssignmentNamespace checks that a namespaced EIP is only assigned to
+// pods and ENIs in another namespace by an EIPAssociation there, which the
+// EIPReferenceGrants in the namespace of the EIP permit. ClusterEIPs may be
+// assigned to any namespace.
+func (r *EIPReconciler) checkAssignmentNamespace(ctx context.Context, eip *awsv1alpha1.EIP) error {
+	target := eip.Spec.Assignment.Namespace
+	if r.ClusterScoped || target == "" || target == eip.Namespace {
+		return nil
+	}
+
+	var grants awsv1alpha1.EIPReferenceGrantList
+	if err := r.List(ctx, &grants, client.InNamespace(eip.Namespace)); err != nil {
+		return err
+	}
+	var eipAssociations awsv1alpha1.EIPAssociationList
+	if err := r.List(ctx, &eipAssociations,
+		client.InNamespace(target),
+		client.MatchingFields{eipRefField: eip.Namespace + "/" + eip.Name},
+	); err != nil {
+		return err
+	}
+	if !isAssignmentPermitted(eip, grants.Items, eipAssociations.Items) {
+		return fmt.Errorf("assigning EIP %s to namespace %s needs an EIPAssociation there, permitted by an EIPReferenceGrant", eip.Name, target)
+	}
+	return nil
+}
+
+// isAssignmentPermitted returns whether the assignment of an EIP to another
+// namespace is requested by one of the associations in that namespace, and
+// permitted by one of the grants in the namespace of the EIP.
+func isAssignmentPermitted(eip *awsv1alpha1.EIP, grants []awsv1alpha1.EIPReferenceGrant, eipAssociations []awsv1alpha1.EIPAssociation) bool {
+	target := eip.Spec.Assignment.Namespace
+	if !isGranted(grants, target, eip.Name) {
+		return false
+	}
+	for i := range eipAssociations {
+		eipAssociation := &eipAssociations[i]
+		if eipAssociation.Namespace != target || !eipAssociation.ObjectMeta.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if getEIPKey(eipAssociation) == (types.NamespacedName{Namespace: eip.Namespace, Name: eip.Name}) &&
+			isSameAssignment(eip.Spec.Assignment, getAssignment(eipAssociation, eip.Namespace)) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *EIPReconciler) getAssignmentTarget(ctx context.Context, eip *awsv1alpha1.EIP) (string, string, error) {
 	modes := 0
 	if eip.Spec.Assignment.PodName != "" {
@@ -337,18 +642,35 @@ func (r *EIPReconciler) getAssignmentTarget(ctx context.Context, eip *awsv1alpha
 	if eip.Spec.Assignment.PrivateIPAddress != "" {
 		modes++
 	}
+	if eip.Spec.Assignment.NodeName != "" {
+		modes++
+	}
 	if modes != 1 {
-		return "", "", fmt.Errorf("exactly one of podName, eni or privateIPAddress needs to be given in assignment")
+		return "", "", fmt.Errorf("exactly one of podName, nodeName, eni or privateIPAddress needs to be given in assignment")
+	}
+
+	namespace := eip.Namespace
+	if eip.Spec.Assignment.Namespace != "" {
+		namespace = eip.Spec.Assignment.Namespace
+	}
+	if namespace == "" && (eip.Spec.Assignment.PodName != "" || eip.Spec.Assignment.ENI != "") {
+		return "", "", fmt.Errorf("namespace needs to be given in assignment")
+	}
+	if err := r.checkAssignmentNamespace(ctx, eip); err != nil {
+		return "", "", err
 	}
 
 	if eip.Spec.Assignment.ENI != "" {
 		var eni awsv1alpha1.ENI
 		if err := r.Get(ctx, types.NamespacedName{
-			Namespace: eip.Namespace,
+			Namespace: namespace,
 			Name:      eip.Spec.Assignment.ENI,
 		}, &eni); err != nil {
 			return "", "", err
 		}
+		if eni.Spec.AWSAccountName != eip.Spec.AWSAccountName || eni.Spec.Region != eip.Spec.Region {
+			return "", "", fmt.Errorf("ENI %s is not in the same AWS account and region as the EIP", eni.Name)
+		}
 
 		index := eip.Spec.Assignment.ENIPrivateIPAddressIndex
 		if index >= len(eni.Status.PrivateIPAddresses) {
@@ -358,8 +680,14 @@ func (r *EIPReconciler) getAssignmentTarget(ctx context.Context, eip *awsv1alpha
 	}
 
 	privateIP := eip.Spec.Assignment.PrivateIPAddress
-	if privateIP == "" {
-		ip, err := r.getPodPrivateIP(ctx, eip.Namespace, eip.Spec.Assignment.PodName)
+	if eip.Spec.Assignment.NodeName != "" {
+		ip, err := r.getNodePrivateIP(ctx, eip.Spec.Assignment.NodeName)
+		if err != nil {
+			return "", "", err
+		}
+		privateIP = ip
+	} else if privateIP == "" {
+		ip, err := r.getPodPrivateIP(ctx, namespace, eip.Spec.Assignment.PodName)
 		if err != nil {
 			return "", "", err
 		}
@@ -385,10 +713,21 @@ func (r *EIPReconciler) assignEIP(ctx context.Context, eip *awsv1alpha1.EIP, log
 		return err
 	}
 
-	log.Info("assigning", "podName", eip.Spec.Assignment.PodName, "privateIP", privateIP, "eni", eni)
+	if eip.Spec.NetworkBorderGroup != "" && eip.Status.NetworkBorderGroup != "" {
+		// EIPs can only be associated with ENIs in their network border group
+		networkBorderGroup, err := r.getENINetworkBorderGroup(ctx, eni)
+		if err != nil {
+			return err
+		}
+		if networkBorderGroup != eip.Status.NetworkBorderGroup {
+			return fmt.Errorf("EIP is in network border group %s, but ENI %s is in %s", eip.Status.NetworkBorderGroup, eni, networkBorderGroup)
+		}
+	}
+
+	log.Info("assigning", "podName", eip.Spec.Assignment.PodName, "nodeName", eip.Spec.Assignment.NodeName, "privateIP", privateIP, "eni", eni)
 
 	resp, err := r.EC2.AssociateAddressWithContext(ctx, &ec2.AssociateAddressInput{
-		AllowReassociation: aws.Bool(eip.Status.State == "reassigning"),
+		AllowReassociation: aws.Bool(eip.Status.State == awsv1alpha1.EIPStateReassigning),
 		AllocationId:       aws.String(eip.Status.AllocationId),
 		NetworkInterfaceId: aws.String(eni),
 		PrivateIpAddress:   aws.String(privateIP),
@@ -399,11 +738,20 @@ func (r *EIPReconciler) assignEIP(ctx context.Context, eip *awsv1alpha1.EIP, log
 
 	log.Info("assigned")
 
-	eip.Status.State = "assigned"
+	if old := eip.Status.Assignment; old != nil && (old.PodName != eip.Spec.Assignment.PodName || old.Namespace != eip.Spec.Assignment.Namespace) {
+		if err := r.reconcilePodAnnotations(ctx, eip, old, false); err != nil {
+			return err
+		}
+	}
+
+	if err := setEIPState(eip, awsv1alpha1.EIPStateAssigned); err != nil {
+		return err
+	}
 	eip.Status.AssociationId = aws.StringValue(resp.AssociationId)
 	eip.Status.Assignment = eip.Spec.Assignment
+	eip.Status.PodAnnotated = false
 	eip.Status.Assignment.PrivateIPAddress = privateIP
-	if err := r.Update(ctx, eip); err != nil {
+	if err := r.updateEIP(ctx, eip); err != nil {
 		return err
 	}
 
@@ -417,19 +765,25 @@ func (r *EIPReconciler) unassignEIP(ctx context.Context, eip *awsv1alpha1.EIP, l
 		AssociationId: aws.String(eip.Status.AssociationId),
 	})
 	if err != nil {
-		if awsErr, ok := err.(awserr.Error); ok && (awsErr.Code() == "InvalidAssociationID.NotFound" || awsErr.Code() == "InvalidNetworkInterfaceID.NotFound") {
-			log.Info("association ID or network interface ID not found; assuming EIP already disassociated", "associationnId", eip.Status.AssociationId)
+		if isAWSErrorCode(err, "InvalidAssociationID.NotFound", "InvalidNetworkInterfaceID.NotFound") {
+			log.Info("association ID or network interface ID not found; assuming EIP already disassociated", "associationId", eip.Status.AssociationId)
 		} else {
-			log.Info(awsErr.Code())
 			return err
 		}
 	}
 
 	log.Info("unassigned")
 
-	eip.Status.State = "allocated"
+	if err := r.reconcilePodAnnotations(ctx, eip, eip.Status.Assignment, false); err != nil {
+		return err
+	}
+
+	if err := setEIPState(eip, awsv1alpha1.EIPStateAllocated); err != nil {
+		return err
+	}
 	eip.Status.Assignment = nil
-	if err := r.Update(ctx, eip); err != nil {
+	eip.Status.PodAnnotated = false
+	if err := r.updateEIP(ctx, eip); err != nil {
 		return err
 	}
 
@@ -437,6 +791,14 @@ func (r *EIPReconciler) unassignEIP(ctx context.Context, eip *awsv1alpha1.EIP, l
 }
 
 func (r *EIPReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.roundRobin = &sync.Map{}
+
+	if r.ClusterScoped {
+		return ctrl.NewControllerManagedBy(mgr).
+			For(&awsv1alpha1.ClusterEIP{}).
+			Complete(r)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&awsv1alpha1.EIP{}).
 		Complete(r)
@@ -0,0 +1,322 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+const (
+	// keys of the generated ConfigMaps
+	inventoryPlainKey = "ips.txt"
+	inventoryJSONKey  = "ips.json"
+	inventoryCIDRKey  = "cidrs.txt"
+
+	// EIPInventoryPath is the path EIPInventories are served at, followed by
+	// <namespace>/<name>.
+	EIPInventoryPath = "/eip-inventories/"
+)
+
+// inventoryEntry is an EIP as listed in the JSON format of an inventory.
+type inventoryEntry struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	AllocationID    string `json:"allocationId"`
+	PublicIPAddress string `json:"publicIPAddress"`
+}
+
+// EIPInventoryReconciler reconciles a EIPInventory object
+type EIPInventoryReconciler struct {
+	client.Client
+	NonCachingClient client.Client
+	Log              logr.Logger
+}
+
+// +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=eipinventories,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+
+func (r *EIPInventoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("eipInventory", req.NamespacedName)
+
+	var inventory awsv1alpha1.EIPInventory
+	if err := r.Get(ctx, req.NamespacedName, &inventory); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !inventory.ObjectMeta.DeletionTimestamp.IsZero() {
+		// the generated ConfigMap is garbage collected
+		return ctrl.Result{}, nil
+	}
+
+	entries, err := getInventoryEntries(ctx, r.Client, &inventory)
+	if err == nil {
+		err = r.reconcileConfigMap(ctx, &inventory, entries, log)
+	}
+	status := awsv1alpha1.EIPInventoryStatus{Count: len(entries)}
+	if err != nil {
+		status = awsv1alpha1.EIPInventoryStatus{Count: inventory.Status.Count, Error: err.Error()}
+	}
+	if status != inventory.Status {
+		inventory.Status = status
+		if updateErr := r.Update(ctx, &inventory); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+	}
+	return ctrl.Result{}, err
+}
+
+// reconcileConfigMap creates or updates the ConfigMap of an inventory.
+// ConfigMaps are read without cache to avoid caching all ConfigMaps of the
+// cluster.
+func (r *EIPInventoryReconciler) reconcileConfigMap(ctx context.Context, inventory *awsv1alpha1.EIPInventory, entries []inventoryEntry, log logr.Logger) error {
+	data, err := formatInventory(entries)
+	if err != nil {
+		return err
+	}
+
+	name := inventory.Spec.ConfigMapName
+	if name == "" {
+		name = inventory.Name
+	}
+	configMap := &corev1.ConfigMap{}
+	err = r.NonCachingClient.Get(ctx, client.ObjectKey{Namespace: inventory.Namespace, Name: name}, configMap)
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: inventory.Namespace,
+				Name:      name,
+			},
+			Data: data,
+		}
+		if err := controllerutil.SetControllerReference(inventory, configMap, r.Scheme()); err != nil {
+			return err
+		}
+		log.Info("creating ConfigMap", "configMap", name, "count", len(entries))
+		return r.NonCachingClient.Create(ctx, configMap)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !metav1.IsControlledBy(configMap, inventory) {
+		return fmt.Errorf("ConfigMap %s is not owned by the inventory", name)
+	}
+	if equalStringMaps(configMap.Data, data) {
+		return nil
+	}
+	configMap.Data = data
+	log.Info("updating ConfigMap", "configMap", name, "count", len(entries))
+	return r.NonCachingClient.Update(ctx, configMap)
+}
+
+// getInventoryEntries returns the allocated EIPs and ClusterEIPs selected by
+// an inventory, ordered by public IP address.
+func getInventoryEntries(ctx context.Context, c client.Reader, inventory *awsv1alpha1.EIPInventory) ([]inventoryEntry, error) {
+	var entries []inventoryEntry
+
+	eipSelector := labels.Everything()
+	if inventory.Spec.EIPSelector != nil {
+		var err error
+		if eipSelector, err = metav1.LabelSelectorAsSelector(inventory.Spec.EIPSelector); err != nil {
+			return nil, fmt.Errorf("invalid eipSelector: %w", err)
+		}
+	}
+	var eips awsv1alpha1.EIPList
+	if err := c.List(ctx, &eips, client.InNamespace(inventory.Namespace), client.MatchingLabelsSelector{Selector: eipSelector}); err != nil {
+		return nil, err
+	}
+	for _, eip := range eips.Items {
+		if eip.Status.PublicIPAddress != "" && eip.Status.State != awsv1alpha1.EIPStateLost {
+			entries = append(entries, inventoryEntry{
+				Name:            eip.Name,
+				Namespace:       eip.Namespace,
+				AllocationID:    eip.Status.AllocationId,
+				PublicIPAddress: eip.Status.PublicIPAddress,
+			})
+		}
+	}
+
+	if inventory.Spec.ClusterEIPSelector != nil {
+		clusterEIPSelector, err := metav1.LabelSelectorAsSelector(inventory.Spec.ClusterEIPSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid clusterEIPSelector: %w", err)
+		}
+		var clusterEIPs awsv1alpha1.ClusterEIPList
+		if err := c.List(ctx, &clusterEIPs, client.MatchingLabelsSelector{Selector: clusterEIPSelector}); err != nil {
+			return nil, err
+		}
+		for _, clusterEIP := range clusterEIPs.Items {
+			if clusterEIP.Status.PublicIPAddress != "" && clusterEIP.Status.State != awsv1alpha1.EIPStateLost {
+				entries = append(entries, inventoryEntry{
+					Name:            clusterEIP.Name,
+					AllocationID:    clusterEIP.Status.AllocationId,
+					PublicIPAddress: clusterEIP.Status.PublicIPAddress,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].PublicIPAddress < entries[j].PublicIPAddress
+	})
+	return entries, nil
+}
+
+// formatInventory returns the inventory as plain list, JSON and CIDR list,
+// by ConfigMap key.
+func formatInventory(entries []inventoryEntry) (map[string]string, error) {
+	if entries == nil {
+		entries = []inventoryEntry{}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain, cidrs strings.Builder
+	for _, entry := range entries {
+		plain.WriteString(entry.PublicIPAddress + "\n")
+		cidrs.WriteString(entry.PublicIPAddress + "/32\n")
+	}
+	return map[string]string{
+		inventoryPlainKey: plain.String(),
+		inventoryJSONKey:  string(data),
+		inventoryCIDRKey:  cidrs.String(),
+	}, nil
+}
+
+func equalStringMaps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
+// findInventoriesForEIP returns the inventories in the namespace of an EIP.
+func (r *EIPInventoryReconciler) findInventoriesForEIP(eip client.Object) []reconcile.Request {
+	var inventories awsv1alpha1.EIPInventoryList
+	if err := r.List(context.Background(), &inventories, client.InNamespace(eip.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list EIP inventories")
+		return nil
+	}
+	return inventoryRequests(inventories.Items, false)
+}
+
+// findInventoriesForClusterEIP returns the inventories listing ClusterEIPs.
+func (r *EIPInventoryReconciler) findInventoriesForClusterEIP(clusterEIP client.Object) []reconcile.Request {
+	var inventories awsv1alpha1.EIPInventoryList
+	if err := r.List(context.Background(), &inventories); err != nil {
+		r.Log.Error(err, "failed to list EIP inventories")
+		return nil
+	}
+	return inventoryRequests(inventories.Items, true)
+}
+
+func inventoryRequests(inventories []awsv1alpha1.EIPInventory, clusterEIPsOnly bool) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, inventory := range inventories {
+		if clusterEIPsOnly && inventory.Spec.ClusterEIPSelector == nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: inventory.Namespace,
+			Name:      inventory.Name,
+		}})
+	}
+	return requests
+}
+
+func (r *EIPInventoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&awsv1alpha1.EIPInventory{}).
+		Watches(&source.Kind{Type: &awsv1alpha1.EIP{}}, handler.EnqueueRequestsFromMapFunc(r.findInventoriesForEIP)).
+		Watches(&source.Kind{Type: &awsv1alpha1.ClusterEIP{}}, handler.EnqueueRequestsFromMapFunc(r.findInventoriesForClusterEIP)).
+		Complete(r)
+}
+
+// EIPInventoryHandler serves the public IP addresses of EIPInventories at
+// EIPInventoryPath<namespace>/<name>, as plain list by default, or in the
+// format given by the format query parameter (plain, json or cidr).
+type EIPInventoryHandler struct {
+	Client client.Reader
+}
+
+func (h *EIPInventoryHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, EIPInventoryPath), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.Error(w, "expected "+EIPInventoryPath+"<namespace>/<name>", http.StatusNotFound)
+		return
+	}
+
+	var inventory awsv1alpha1.EIPInventory
+	if err := h.Client.Get(req.Context(), client.ObjectKey{Namespace: parts[0], Name: parts[1]}, &inventory); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	entries, err := getInventoryEntries(req.Context(), h.Client, &inventory)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := formatInventory(entries)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch format := req.URL.Query().Get("format"); format {
+	case "", "plain":
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, data[inventoryPlainKey])
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, data[inventoryJSONKey])
+	case "cidr":
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, data[inventoryCIDRKey])
+	default:
+		http.Error(w, fmt.Sprintf("unsupported format %q, expected plain, json or cidr", format), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatInventory(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []inventoryEntry
+		want    map[string]string
+	}{
+		{"empty", nil, map[string]string{
+			inventoryPlainKey: "",
+			inventoryJSONKey:  "[]",
+			inventoryCIDRKey:  "",
+		}},
+		{"EIPs and ClusterEIPs", []inventoryEntry{
+			{Name: "a", Namespace: "ns", AllocationID: "eipalloc-1", PublicIPAddress: "1.2.3.4"},
+			{Name: "b", AllocationID: "eipalloc-2", PublicIPAddress: "5.6.7.8"},
+		}, map[string]string{
+			inventoryPlainKey: "1.2.3.4\n5.6.7.8\n",
+			inventoryJSONKey:  `[{"name":"a","namespace":"ns","allocationId":"eipalloc-1","publicIPAddress":"1.2.3.4"},{"name":"b","allocationId":"eipalloc-2","publicIPAddress":"5.6.7.8"}]`,
+			inventoryCIDRKey:  "1.2.3.4/32\n5.6.7.8/32\n",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := formatInventory(test.entries)
+			if err != nil {
+				t.Fatalf("formatInventory() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("formatInventory() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
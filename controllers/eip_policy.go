@@ -0,0 +1,308 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=eippolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=clustereippolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// getPolicies returns the EIPPolicies in the given namespace and the
+// ClusterEIPPolicies selecting it.
+func getPolicies(ctx context.Context, c client.Reader, namespace string) ([]awsv1alpha1.EIPPolicySpec, error) {
+	if namespace == "" {
+		// cluster-scoped resources are not restricted
+		return nil, nil
+	}
+
+	var policies []awsv1alpha1.EIPPolicySpec
+
+	var eipPolicies awsv1alpha1.EIPPolicyList
+	if err := c.List(ctx, &eipPolicies, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, policy := range eipPolicies.Items {
+		policies = append(policies, policy.Spec)
+	}
+
+	var clusterEIPPolicies awsv1alpha1.ClusterEIPPolicyList
+	if err := c.List(ctx, &clusterEIPPolicies); err != nil {
+		return nil, err
+	}
+	if len(clusterEIPPolicies.Items) == 0 {
+		return policies, nil
+	}
+
+	var ns corev1.Namespace
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		return nil, err
+	}
+	for _, policy := range clusterEIPPolicies.Items {
+		if policy.Spec.NamespaceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespaceSelector in ClusterEIPPolicy %s: %w", policy.Name, err)
+			}
+			if !selector.Matches(labels.Set(ns.Labels)) {
+				continue
+			}
+		}
+		policies = append(policies, policy.Spec.EIPPolicySpec)
+	}
+	return policies, nil
+}
+
+// countEIPs returns the number of EIPs in a namespace, excluding the one with
+// the given UID. If allocatedOnly is set, only EIPs that are allocated in AWS
+// are counted.
+func countEIPs(ctx context.Context, c client.Reader, namespace string, exclude types.UID, allocatedOnly bool) (int, error) {
+	var eips awsv1alpha1.EIPList
+	if err := c.List(ctx, &eips, client.InNamespace(namespace)); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, eip := range eips.Items {
+		if eip.UID == exclude || (allocatedOnly && eip.Status.AllocationId == "") {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// countENIs returns the number of ENIs in a namespace, including the ENIs of
+// ENIPools, excluding the ENI or ENIPool with the given UID. If createdOnly is
+// set, only ENIs that exist in AWS are counted; otherwise ENIPools count with
+// their size.
+func countENIs(ctx context.Context, c client.Reader, namespace string, exclude types.UID, createdOnly bool) (int, error) {
+	var enis awsv1alpha1.ENIList
+	if err := c.List(ctx, &enis, client.InNamespace(namespace)); err != nil {
+		return 0, err
+	}
+	var pools awsv1alpha1.ENIPoolList
+	if err := c.List(ctx, &pools, client.InNamespace(namespace)); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, eni := range enis.Items {
+		if eni.UID == exclude || (createdOnly && eni.Status.NetworkInterfaceID == "") {
+			continue
+		}
+		count++
+	}
+	for _, pool := range pools.Items {
+		if pool.UID == exclude {
+			continue
+		}
+		if createdOnly {
+			count += len(pool.Status.NetworkInterfaceIDs)
+		} else {
+			count += pool.Spec.Size
+		}
+	}
+	return count, nil
+}
+
+// checkEIPQuota returns an error if the number of EIPs exceeds the maximum of
+// any of the policies.
+func checkEIPQuota(policies []awsv1alpha1.EIPPolicySpec, count int) error {
+	for _, policy := range policies {
+		if policy.MaxEIPs != nil && count > int(*policy.MaxEIPs) {
+			return fmt.Errorf("quota exceeded: at most %d EIPs are allowed in the namespace", *policy.MaxEIPs)
+		}
+	}
+	return nil
+}
+
+// checkENIQuota returns an error if the number of ENIs exceeds the maximum of
+// any of the policies.
+func checkENIQuota(policies []awsv1alpha1.EIPPolicySpec, count int) error {
+	for _, policy := range policies {
+		if policy.MaxENIs != nil && count > int(*policy.MaxENIs) {
+			return fmt.Errorf("quota exceeded: at most %d ENIs are allowed in the namespace", *policy.MaxENIs)
+		}
+	}
+	return nil
+}
+
+// checkEIPPolicies checks an EIP spec against the policies. If the previous
+// spec is given, only the fields that changed are checked, so that existing
+// EIPs can still be updated after a policy has been tightened.
+func checkEIPPolicies(policies []awsv1alpha1.EIPPolicySpec, spec, old *awsv1alpha1.EIPSpec) error {
+	for _, policy := range policies {
+		if len(policy.AllowedPublicIPv4Pools) > 0 && (old == nil || spec.PublicIPAddress != old.PublicIPAddress ||
+			spec.PublicIPv4Pool != old.PublicIPv4Pool || !equalStrings(spec.PublicIPv4Pools, old.PublicIPv4Pools) ||
+			spec.IPAMPoolID != old.IPAMPoolID) {
+			if spec.PublicIPAddress != "" {
+				return fmt.Errorf("publicIPAddress is not allowed, as EIPs need to be allocated from one of the pools %v", policy.AllowedPublicIPv4Pools)
+			}
+			// the public IPv4 pools of an IPAM pool are only known, and
+			// checked, when allocating
+			pools := spec.PublicIPv4Pools
+			if spec.PublicIPv4Pool != "" {
+				pools = []string{spec.PublicIPv4Pool}
+			} else if len(pools) == 0 && spec.IPAMPoolID == "" {
+				pools = []string{awsv1alpha1.AmazonPublicIPv4Pool}
+			}
+			for _, pool := range pools {
+				if err := checkPublicIPv4Pool(&policy, pool); err != nil {
+					return err
+				}
+			}
+		}
+
+		if old == nil || !reflect.DeepEqual(spec.Tags, old.Tags) {
+			if err := checkRequiredTags(&policy, spec.Tags); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkPublicIPv4Pool(policy *awsv1alpha1.EIPPolicySpec, pool string) error {
+	if len(policy.AllowedPublicIPv4Pools) > 0 && !containsString(policy.AllowedPublicIPv4Pools, pool) {
+		return fmt.Errorf("public IPv4 pool %s is not allowed, allowed pools are %v", pool, policy.AllowedPublicIPv4Pools)
+	}
+	return nil
+}
+
+// eniPolicySubject is the part of an ENI or ENIPool spec restricted by
+// policies.
+type eniPolicySubject struct {
+	SubnetID       string
+	SecurityGroups []string
+	Tags           *map[string]string
+}
+
+// checkENIPolicies checks an ENI or ENIPool against the policies. If the
+// previous values are given, only the ones that changed are checked.
+func checkENIPolicies(policies []awsv1alpha1.EIPPolicySpec, subject, old *eniPolicySubject) error {
+	for _, policy := range policies {
+		if len(policy.AllowedSubnetIDs) > 0 && (old == nil || subject.SubnetID != old.SubnetID) &&
+			!containsString(policy.AllowedSubnetIDs, subject.SubnetID) {
+			return fmt.Errorf("subnet %s is not allowed, allowed subnets are %v", subject.SubnetID, policy.AllowedSubnetIDs)
+		}
+
+		if len(policy.AllowedSecurityGroups) > 0 && (old == nil || !equalStrings(subject.SecurityGroups, old.SecurityGroups)) {
+			for _, securityGroup := range subject.SecurityGroups {
+				if !containsString(policy.AllowedSecurityGroups, securityGroup) {
+					return fmt.Errorf("security group %s is not allowed, allowed security groups are %v", securityGroup, policy.AllowedSecurityGroups)
+				}
+			}
+		}
+
+		if old == nil || !reflect.DeepEqual(subject.Tags, old.Tags) {
+			if err := checkRequiredTags(&policy, subject.Tags); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkRequiredTags(policy *awsv1alpha1.EIPPolicySpec, tags *map[string]string) error {
+	for _, key := range policy.RequiredTags {
+		if tags == nil {
+			return fmt.Errorf("tag %s is required", key)
+		}
+		if _, ok := (*tags)[key]; !ok {
+			return fmt.Errorf("tag %s is required", key)
+		}
+	}
+	return nil
+}
+
+func getENIPolicySubject(eni *awsv1alpha1.ENI) *eniPolicySubject {
+	return &eniPolicySubject{
+		SubnetID:       eni.Spec.SubnetID,
+		SecurityGroups: eni.Spec.SecurityGroups,
+		Tags:           eni.Spec.Tags,
+	}
+}
+
+func getENIPoolPolicySubject(pool *awsv1alpha1.ENIPool) *eniPolicySubject {
+	return &eniPolicySubject{
+		SubnetID:       pool.Spec.SubnetID,
+		SecurityGroups: pool.Spec.SecurityGroups,
+		Tags:           pool.Spec.Tags,
+	}
+}
+
+// enforceEIPPolicies checks an EIP that is about to be allocated against the
+// policies of its namespace, in case the admission webhook is not deployed
+// or the policies changed since the EIP was created.
+func enforceEIPPolicies(ctx context.Context, c client.Reader, eip *awsv1alpha1.EIP) error {
+	policies, err := getPolicies(ctx, c, eip.Namespace)
+	if err != nil || len(policies) == 0 {
+		return err
+	}
+	if err := checkEIPPolicies(policies, &eip.Spec, nil); err != nil {
+		return err
+	}
+	count, err := countEIPs(ctx, c, eip.Namespace, eip.UID, true)
+	if err != nil {
+		return err
+	}
+	return checkEIPQuota(policies, count+1)
+}
+
+// enforcePublicIPv4PoolPolicies checks the public IPv4 pool chosen for an
+// EIP allocated from an IPAM pool against the policies of its namespace.
+func enforcePublicIPv4PoolPolicies(ctx context.Context, c client.Reader, namespace, pool string) error {
+	policies, err := getPolicies(ctx, c, namespace)
+	if err != nil {
+		return err
+	}
+	for i := range policies {
+		if err := checkPublicIPv4Pool(&policies[i], pool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enforceENIPolicies checks an ENI that is about to be created, either for an
+// ENI or an ENIPool, against the policies of its namespace.
+func enforceENIPolicies(ctx context.Context, c client.Reader, namespace string, subject *eniPolicySubject, exclude types.UID) error {
+	policies, err := getPolicies(ctx, c, namespace)
+	if err != nil || len(policies) == 0 {
+		return err
+	}
+	if err := checkENIPolicies(policies, subject, nil); err != nil {
+		return err
+	}
+	count, err := countENIs(ctx, c, namespace, exclude, true)
+	if err != nil {
+		return err
+	}
+	return checkENIQuota(policies, count+1)
+}
@@ -0,0 +1,112 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+func TestCheckEIPPolicies(t *testing.T) {
+	policies := []awsv1alpha1.EIPPolicySpec{{
+		AllowedPublicIPv4Pools: []string{"ipv4pool-ec2-1", awsv1alpha1.AmazonPublicIPv4Pool},
+		RequiredTags:           []string{"owner"},
+	}}
+	tags := &map[string]string{"owner": "team"}
+
+	tests := []struct {
+		name    string
+		spec    awsv1alpha1.EIPSpec
+		old     *awsv1alpha1.EIPSpec
+		wantErr bool
+	}{
+		{"allowed pool", awsv1alpha1.EIPSpec{PublicIPv4Pool: "ipv4pool-ec2-1", Tags: tags}, nil, false},
+		{"amazon pool", awsv1alpha1.EIPSpec{Tags: tags}, nil, false},
+		{"disallowed pool", awsv1alpha1.EIPSpec{PublicIPv4Pool: "ipv4pool-ec2-2", Tags: tags}, nil, true},
+		{"disallowed pool in list", awsv1alpha1.EIPSpec{PublicIPv4Pools: []string{"ipv4pool-ec2-1", "ipv4pool-ec2-2"}, Tags: tags}, nil, true},
+		{"IPAM pool, checked when allocating", awsv1alpha1.EIPSpec{IPAMPoolID: "ipam-pool-1", Tags: tags}, nil, false},
+		{"specific address", awsv1alpha1.EIPSpec{PublicIPAddress: "1.2.3.4", Tags: tags}, nil, true},
+		{"missing tag", awsv1alpha1.EIPSpec{}, nil, true},
+		{"unchanged on update", awsv1alpha1.EIPSpec{PublicIPv4Pool: "ipv4pool-ec2-2"}, &awsv1alpha1.EIPSpec{PublicIPv4Pool: "ipv4pool-ec2-2"}, false},
+		{"tags removed on update", awsv1alpha1.EIPSpec{}, &awsv1alpha1.EIPSpec{Tags: tags}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkEIPPolicies(policies, &test.spec, test.old)
+			if (err != nil) != test.wantErr {
+				t.Errorf("checkEIPPolicies() = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPublicIPv4Pool(t *testing.T) {
+	policy := &awsv1alpha1.EIPPolicySpec{AllowedPublicIPv4Pools: []string{"ipv4pool-ec2-1"}}
+	if err := checkPublicIPv4Pool(policy, "ipv4pool-ec2-1"); err != nil {
+		t.Errorf("allowed pool: got %v", err)
+	}
+	if err := checkPublicIPv4Pool(policy, "ipv4pool-ec2-2"); err == nil {
+		t.Error("disallowed pool: got no error")
+	}
+	if err := checkPublicIPv4Pool(&awsv1alpha1.EIPPolicySpec{}, "ipv4pool-ec2-2"); err != nil {
+		t.Errorf("no allowed pools: got %v", err)
+	}
+}
+
+func TestCheckENIPolicies(t *testing.T) {
+	policies := []awsv1alpha1.EIPPolicySpec{{
+		AllowedSubnetIDs:      []string{"subnet-1"},
+		AllowedSecurityGroups: []string{"sg-1", "default"},
+	}}
+
+	tests := []struct {
+		name    string
+		subject eniPolicySubject
+		old     *eniPolicySubject
+		wantErr bool
+	}{
+		{"allowed", eniPolicySubject{SubnetID: "subnet-1", SecurityGroups: []string{"sg-1", "default"}}, nil, false},
+		{"disallowed subnet", eniPolicySubject{SubnetID: "subnet-2"}, nil, true},
+		{"disallowed security group", eniPolicySubject{SubnetID: "subnet-1", SecurityGroups: []string{"sg-2"}}, nil, true},
+		{"unchanged on update", eniPolicySubject{SubnetID: "subnet-2"}, &eniPolicySubject{SubnetID: "subnet-2"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkENIPolicies(policies, &test.subject, test.old)
+			if (err != nil) != test.wantErr {
+				t.Errorf("checkENIPolicies() = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	max := int32(2)
+	policies := []awsv1alpha1.EIPPolicySpec{{}, {MaxEIPs: &max}}
+
+	if err := checkEIPQuota(policies, 2); err != nil {
+		t.Errorf("checkEIPQuota(2) = %v, want nil", err)
+	}
+	if err := checkEIPQuota(policies, 3); err == nil {
+		t.Error("checkEIPQuota(3) = nil, want error")
+	}
+	if err := checkENIQuota(policies, 3); err != nil {
+		t.Errorf("checkENIQuota(3) = %v, want nil", err)
+	}
+}
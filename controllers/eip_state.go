@@ -0,0 +1,86 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// eipTransitions lists the states each state of an EIP may change to, see
+// the diagram at EIPStatus.State. The empty state is the one of new EIPs.
+var eipTransitions = map[awsv1alpha1.EIPState][]awsv1alpha1.EIPState{
+	"":                              {awsv1alpha1.EIPStateAllocating},
+	awsv1alpha1.EIPStateAllocating:  {awsv1alpha1.EIPStateAllocated},
+	awsv1alpha1.EIPStateAllocated:   {awsv1alpha1.EIPStateAssigning, awsv1alpha1.EIPStateReleasing, awsv1alpha1.EIPStateLost},
+	awsv1alpha1.EIPStateAssigning:   {awsv1alpha1.EIPStateAssigned, awsv1alpha1.EIPStateAllocated, awsv1alpha1.EIPStateLost},
+	awsv1alpha1.EIPStateAssigned:    {awsv1alpha1.EIPStateReassigning, awsv1alpha1.EIPStateUnassigning, awsv1alpha1.EIPStateLost},
+	awsv1alpha1.EIPStateReassigning: {awsv1alpha1.EIPStateAssigned, awsv1alpha1.EIPStateUnassigning, awsv1alpha1.EIPStateLost},
+	awsv1alpha1.EIPStateUnassigning: {awsv1alpha1.EIPStateAllocated, awsv1alpha1.EIPStateLost},
+	awsv1alpha1.EIPStateReleasing:   nil,
+	awsv1alpha1.EIPStateLost:        {awsv1alpha1.EIPStateAllocating},
+}
+
+// isKnownEIPState returns whether the state is one of the lifecycle states.
+func isKnownEIPState(state awsv1alpha1.EIPState) bool {
+	_, ok := eipTransitions[state]
+	return ok && state != ""
+}
+
+// checkEIPTransition returns an error if an EIP may not change from one
+// state to the other.
+func checkEIPTransition(from, to awsv1alpha1.EIPState) error {
+	for _, state := range eipTransitions[from] {
+		if state == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("illegal EIP state transition from %q to %q", from, to)
+}
+
+// setEIPState changes the state of an EIP, failing if the transition is not
+// allowed.
+func setEIPState(eip *awsv1alpha1.EIP, state awsv1alpha1.EIPState) error {
+	if err := checkEIPTransition(eip.Status.State, state); err != nil {
+		return err
+	}
+	forceEIPState(eip, state)
+	return nil
+}
+
+// forceEIPState changes the state of an EIP without checking the transition.
+func forceEIPState(eip *awsv1alpha1.EIP, state awsv1alpha1.EIPState) {
+	now := metav1.Now()
+	eip.Status.State = state
+	eip.Status.LastTransitionTime = &now
+}
+
+// recoverEIPState returns the state an EIP with an unknown state, e.g. one
+// whose status was lost or that was created before the finalizer was added,
+// is in, judging by the rest of its status.
+func recoverEIPState(eip *awsv1alpha1.EIP) awsv1alpha1.EIPState {
+	if eip.Status.AllocationId == "" {
+		return awsv1alpha1.EIPStateAllocating
+	}
+	if eip.Status.AssociationId != "" && eip.Status.Assignment != nil {
+		// reassigned or unassigned as required afterwards
+		return awsv1alpha1.EIPStateAssigned
+	}
+	return awsv1alpha1.EIPStateAllocated
+}
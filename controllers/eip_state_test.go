@@ -0,0 +1,103 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+func TestCheckEIPTransition(t *testing.T) {
+	allowed := map[[2]awsv1alpha1.EIPState]bool{
+		{"", awsv1alpha1.EIPStateAllocating}:                               true,
+		{awsv1alpha1.EIPStateAllocating, awsv1alpha1.EIPStateAllocated}:    true,
+		{awsv1alpha1.EIPStateAllocated, awsv1alpha1.EIPStateAssigning}:     true,
+		{awsv1alpha1.EIPStateAllocated, awsv1alpha1.EIPStateReleasing}:     true,
+		{awsv1alpha1.EIPStateAssigning, awsv1alpha1.EIPStateAssigned}:      true,
+		{awsv1alpha1.EIPStateAssigning, awsv1alpha1.EIPStateAllocated}:     true,
+		{awsv1alpha1.EIPStateAssigned, awsv1alpha1.EIPStateReassigning}:    true,
+		{awsv1alpha1.EIPStateAssigned, awsv1alpha1.EIPStateUnassigning}:    true,
+		{awsv1alpha1.EIPStateReassigning, awsv1alpha1.EIPStateAssigned}:    true,
+		{awsv1alpha1.EIPStateReassigning, awsv1alpha1.EIPStateUnassigning}: true,
+		{awsv1alpha1.EIPStateUnassigning, awsv1alpha1.EIPStateAllocated}:   true,
+		{awsv1alpha1.EIPStateAllocated, awsv1alpha1.EIPStateLost}:          true,
+		{awsv1alpha1.EIPStateAssigning, awsv1alpha1.EIPStateLost}:          true,
+		{awsv1alpha1.EIPStateAssigned, awsv1alpha1.EIPStateLost}:           true,
+		{awsv1alpha1.EIPStateReassigning, awsv1alpha1.EIPStateLost}:        true,
+		{awsv1alpha1.EIPStateUnassigning, awsv1alpha1.EIPStateLost}:        true,
+		{awsv1alpha1.EIPStateLost, awsv1alpha1.EIPStateAllocating}:         true,
+	}
+	states := []awsv1alpha1.EIPState{
+		"",
+		awsv1alpha1.EIPStateAllocating,
+		awsv1alpha1.EIPStateAllocated,
+		awsv1alpha1.EIPStateAssigning,
+		awsv1alpha1.EIPStateAssigned,
+		awsv1alpha1.EIPStateReassigning,
+		awsv1alpha1.EIPStateUnassigning,
+		awsv1alpha1.EIPStateReleasing,
+		awsv1alpha1.EIPStateLost,
+		"unknown",
+	}
+
+	for _, from := range states {
+		for _, to := range states {
+			err := checkEIPTransition(from, to)
+			if want := allowed[[2]awsv1alpha1.EIPState{from, to}]; (err == nil) != want {
+				t.Errorf("checkEIPTransition(%q, %q) = %v, want allowed %v", from, to, err, want)
+			}
+		}
+	}
+}
+
+func TestSetEIPState(t *testing.T) {
+	eip := &awsv1alpha1.EIP{Status: awsv1alpha1.EIPStatus{State: awsv1alpha1.EIPStateAllocated}}
+	if err := setEIPState(eip, awsv1alpha1.EIPStateAssigned); err == nil {
+		t.Errorf("setEIPState() allowed skipping assigning")
+	}
+	if eip.Status.State != awsv1alpha1.EIPStateAllocated || eip.Status.LastTransitionTime != nil {
+		t.Errorf("setEIPState() changed the status of a rejected transition: %+v", eip.Status)
+	}
+
+	if err := setEIPState(eip, awsv1alpha1.EIPStateAssigning); err != nil {
+		t.Fatalf("setEIPState() error = %v", err)
+	}
+	if eip.Status.State != awsv1alpha1.EIPStateAssigning || eip.Status.LastTransitionTime == nil {
+		t.Errorf("setEIPState() did not change the state: %+v", eip.Status)
+	}
+}
+
+func TestRecoverEIPState(t *testing.T) {
+	tests := []struct {
+		name   string
+		status awsv1alpha1.EIPStatus
+		want   awsv1alpha1.EIPState
+	}{
+		{"not allocated", awsv1alpha1.EIPStatus{}, awsv1alpha1.EIPStateAllocating},
+		{"allocated", awsv1alpha1.EIPStatus{State: "bogus", AllocationId: "eipalloc-1"}, awsv1alpha1.EIPStateAllocated},
+		{"assigned", awsv1alpha1.EIPStatus{AllocationId: "eipalloc-1", AssociationId: "eipassoc-1", Assignment: &awsv1alpha1.EIPAssignment{PodName: "pod"}}, awsv1alpha1.EIPStateAssigned},
+		{"association without assignment", awsv1alpha1.EIPStatus{AllocationId: "eipalloc-1", AssociationId: "eipassoc-1"}, awsv1alpha1.EIPStateAllocated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := recoverEIPState(&awsv1alpha1.EIP{Status: test.status}); got != test.want {
+				t.Errorf("recoverEIPState() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -18,8 +18,9 @@ package controllers
 import (
 	"context"
 	"errors"
-
-	//"strings"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -27,37 +28,82 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 
 	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
 )
 
+// eniPollIntervals defines how often ENIs in transitional states are polled,
+// as EC2 does not notify about the progress of these operations. ENIs in
+// other states are only reconciled on changes.
+var eniPollIntervals = map[awsv1alpha1.ENIState]time.Duration{
+	awsv1alpha1.ENIStateCreating:  5 * time.Second,
+	awsv1alpha1.ENIStateAttaching: 3 * time.Second,
+	awsv1alpha1.ENIStateDetaching: 3 * time.Second,
+	awsv1alpha1.ENIStateDeleting:  3 * time.Second,
+}
+
 // ENIReconciler reconciles a ENI object
 type ENIReconciler struct {
 	client.Client
 	NonCachingClient client.Client
 	Log              logr.Logger
-	EC2              *ec2.EC2
+	EC2              ec2iface.EC2API
+	EC2Clients       *EC2Clients
 	Tags             map[string]string
+
+	// ClusterName is tagged on created resources to mark them as owned by
+	// the cluster, see ownershipTags.
+	ClusterName string
+	// AdoptUntagged allows adopting resources that are not tagged as owned
+	// by any cluster, e.g. created by earlier versions of the operator.
+	AdoptUntagged bool
+	// DryRun skips writes to Kubernetes that follow from planned AWS
+	// calls, e.g. to pods, and records them as planned actions instead.
+	DryRun bool
+
+	// region of EC2, set by withEC2Client
+	region string
+	// shared by the copies of the reconciler returned by withEC2Client
+	subnetCIDRs *sync.Map
 }
 
 // +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=enis,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=enis/status,verbs=get;update;patch
 
 func (r *ENIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	//log := r.Log.WithValues("eni", req.NamespacedName)
+	result, err := r.reconcile(ctx, req)
+	err = transientAWSError(err)
+	logPlannedAction(r.Log.WithValues("eni", req.NamespacedName), err)
+	if err := r.updateSyncedCondition(ctx, req.NamespacedName, err); err != nil {
+		return ctrl.Result{}, err
+	}
+	return awsErrorResult(result, err)
+}
+
+func (r *ENIReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("eni", req.NamespacedName)
 
 	var eni awsv1alpha1.ENI
 	if err := r.Get(ctx, req.NamespacedName, &eni); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	clientReconciler, err := r.withEC2Client(ctx, eni.Spec.AWSAccountName, eni.Spec.Region, eni.Namespace)
+	if err != nil {
+		return ctrl.Result{}, r.setError(ctx, &eni, err)
+	}
+	r = clientReconciler
+
 	if eni.ObjectMeta.DeletionTimestamp.IsZero() {
 		if !containsString(eni.ObjectMeta.Finalizers, finalizerName) {
+			// add finalizer, set initial state
 			eni.ObjectMeta.Finalizers = append(eni.ObjectMeta.Finalizers, finalizerName)
+			eni.Status.State = awsv1alpha1.ENIStateCreating
 			return ctrl.Result{}, r.Update(context.Background(), &eni)
 		}
 
@@ -67,6 +113,24 @@ func (r *ENIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		}
 
 		if eni.Status.NetworkInterfaceID == "" {
+			if eni.Spec.PoolName != "" {
+				claimed, err := r.claimFromPool(ctx, &eni)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+				if claimed {
+					// description, security groups, private IP addresses and tags are reconciled as usual
+					return r.setState(ctx, &eni, awsv1alpha1.ENIStateAvailable)
+				}
+			}
+
+			if err := enforceENIPolicies(ctx, r.Client, eni.Namespace, getENIPolicySubject(&eni), eni.UID); err != nil {
+				return ctrl.Result{}, r.setError(ctx, &eni, err)
+			}
+			if err := validateSubnetAndSecurityGroups(ctx, r.EC2, r.region, eni.Spec.SubnetID, eni.Spec.SecurityGroups); err != nil {
+				return ctrl.Result{}, r.setError(ctx, &eni, err)
+			}
+
 			input := &ec2.CreateNetworkInterfaceInput{
 				SubnetId:    aws.String(eni.Spec.SubnetID),
 				Groups:      securityGroupIDs,
@@ -78,8 +142,8 @@ func (r *ENIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 
 			tags := ec2.TagSpecification{
 				ResourceType: aws.String("network-interface"),
+				Tags:         r.combineDefaultAndDefinedTags(&eni),
 			}
-			tags.Tags = convertMapToTags(r.Tags)
 			input.TagSpecifications = []*ec2.TagSpecification{&tags}
 
 			resp, err := r.EC2.CreateNetworkInterface(input)
@@ -89,24 +153,45 @@ func (r *ENIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 			eni.Status.NetworkInterfaceID = aws.StringValue(resp.NetworkInterface.NetworkInterfaceId)
 			eni.Status.MacAddress = aws.StringValue(resp.NetworkInterface.MacAddress)
 			eni.Status.PrivateIPAddresses = r.getPrivateIPAddresses(resp.NetworkInterface.PrivateIpAddresses)
-			if err := r.Update(ctx, &eni); err != nil {
-				return ctrl.Result{}, err
-			}
-			if int64(len(eni.Status.PrivateIPAddresses)) != 1+eni.Spec.SecondaryPrivateIPAddressCount {
-				return ctrl.Result{
-					RequeueAfter: 5 * time.Second,
-				}, nil
-			}
-			return ctrl.Result{}, nil
+			eni.Status.Error = ""
+			return r.setState(ctx, &eni, awsv1alpha1.ENIStateCreating)
 		}
 
-		resp, err := r.EC2.DescribeNetworkInterfaces(&ec2.DescribeNetworkInterfacesInput{
-			NetworkInterfaceIds: []*string{aws.String(eni.Status.NetworkInterfaceID)},
-		})
+		eniInfo, err := describeNetworkInterface(ctx, r.EC2, eni.Status.NetworkInterfaceID)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
-		eniInfo := resp.NetworkInterfaces[0]
+		if isClaimPending(&eni, eniInfo.TagSet) {
+			if err := r.completeClaim(ctx, &eni, nil); err != nil {
+				return ctrl.Result{}, err
+			}
+			return r.setState(ctx, &eni, awsv1alpha1.ENIStateAvailable)
+		}
+		if err := ensureOwnership(ctx, r.EC2, "ENI", eni.Status.NetworkInterfaceID, eniInfo.TagSet, &eni, r.ClusterName, r.AdoptUntagged, true, log); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		attachmentStatus := ""
+		if eniInfo.Attachment != nil {
+			attachmentStatus = aws.StringValue(eniInfo.Attachment.Status)
+		}
+
+		addressesAssigned := int64(len(eniInfo.PrivateIpAddresses)) >= 1+eni.Spec.SecondaryPrivateIPAddressCount
+		if next, wait := nextENIState(eni.Status.State, attachmentStatus, addressesAssigned); wait {
+			return ctrl.Result{RequeueAfter: eniPollIntervals[eni.Status.State]}, nil
+		} else if next != eni.Status.State {
+			switch {
+			case eni.Status.State == awsv1alpha1.ENIStateCreating:
+				eni.Status.PrivateIPAddresses = r.getPrivateIPAddresses(eniInfo.PrivateIpAddresses)
+			case eni.Status.State == awsv1alpha1.ENIStateAttaching && next == awsv1alpha1.ENIStateAttached:
+				if eni.Spec.Attachment != nil {
+					eni.Status.Attachment = observedAttachment(eni.Spec.Attachment, eniInfo.Attachment)
+				}
+			case eni.Status.State != "" && next == awsv1alpha1.ENIStateAvailable:
+				eni.Status.Attachment = nil
+			}
+			return r.setState(ctx, &eni, next)
+		}
 
 		// reconcile description and security groups
 		if aws.StringValue(eniInfo.Description) != eni.Spec.Description {
@@ -144,6 +229,22 @@ func (r *ENIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 			}
 		}
 
+		// reconcile source/destination check
+		if eni.Spec.SourceDestCheck != nil && *eni.Spec.SourceDestCheck != aws.BoolValue(eniInfo.SourceDestCheck) {
+			_, err = r.EC2.ModifyNetworkInterfaceAttribute(&ec2.ModifyNetworkInterfaceAttributeInput{
+				NetworkInterfaceId: aws.String(eni.Status.NetworkInterfaceID),
+				SourceDestCheck:    &ec2.AttributeBooleanValue{Value: eni.Spec.SourceDestCheck},
+			})
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			eniInfo.SourceDestCheck = eni.Spec.SourceDestCheck
+		}
+		if eni.Status.SourceDestCheck == nil || *eni.Status.SourceDestCheck != aws.BoolValue(eniInfo.SourceDestCheck) {
+			eni.Status.SourceDestCheck = aws.Bool(aws.BoolValue(eniInfo.SourceDestCheck))
+			return ctrl.Result{}, r.Update(ctx, &eni)
+		}
+
 		// reconcile secondary IP address count
 		actualNum := int64(len(eniInfo.PrivateIpAddresses))
 		desiredNum := 1 + eni.Spec.SecondaryPrivateIPAddressCount
@@ -166,9 +267,7 @@ func (r *ENIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 			if err != nil {
 				return ctrl.Result{}, err
 			}
-			return ctrl.Result{
-				RequeueAfter: 5 * time.Second,
-			}, nil
+			return ctrl.Result{Requeue: true}, nil
 		}
 		if len(eni.Status.PrivateIPAddresses) != len(eniInfo.PrivateIpAddresses) {
 			eni.Status.PrivateIPAddresses = r.getPrivateIPAddresses(eniInfo.PrivateIpAddresses)
@@ -180,68 +279,98 @@ func (r *ENIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 			return ctrl.Result{}, err
 		}
 
-		// reconcile pod attachment
-		if eni.Spec.Attachment == nil {
-			if eniInfo.Attachment == nil || aws.StringValue(eniInfo.Attachment.Status) != "attached" {
-				return ctrl.Result{}, nil
-			} else {
-				err = r.detachENI(aws.StringValue(eniInfo.Attachment.AttachmentId))
-				if err != nil {
-					return ctrl.Result{}, err
-				}
+		// reconcile Multus network attachment definition
+		if err := r.reconcileNetworkAttachmentDefinition(ctx, &eni); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// reconcile attachment
+		desiredInstanceID := ""
+		if eni.Spec.Attachment != nil {
+			if desiredInstanceID, err = r.getDesiredInstanceID(&eni); err != nil {
+				return ctrl.Result{}, r.setError(ctx, &eni, err)
 			}
-		} else {
-			desiredInstanceID, err := r.getInstanceIDOfPod(eni.Namespace, eni.Spec.Attachment.PodName)
+		}
+		switch getENIAttachmentAction(&eni, eniInfo.Attachment, desiredInstanceID) {
+		case eniAttachmentAttach:
+			deviceIndex, networkCardIndex, err := r.attachENI(eni.Status.NetworkInterfaceID, desiredInstanceID, eni.Spec.Attachment)
 			if err != nil {
-				return ctrl.Result{}, err
+				return ctrl.Result{}, r.setError(ctx, &eni, err)
 			}
-			if eniInfo.Attachment == nil {
-				err = r.attachENI(eni.Status.NetworkInterfaceID, desiredInstanceID)
-				if err != nil {
+
+			attachment := *eni.Spec.Attachment
+			attachment.InstanceID = desiredInstanceID
+			attachment.DeviceIndex = aws.Int64(deviceIndex)
+			attachment.NetworkCardIndex = aws.Int64(networkCardIndex)
+			eni.Status.Attachment = &attachment
+			eni.Status.Error = ""
+			return r.setState(ctx, &eni, awsv1alpha1.ENIStateAttaching)
+		case eniAttachmentDetach:
+			err = r.detachENI(aws.StringValue(eniInfo.Attachment.AttachmentId))
+			if err != nil {
+				if !isAWSErrorCode(err, "InvalidAttachmentID.NotFound") {
 					return ctrl.Result{}, err
 				}
-			} else {
-				if desiredInstanceID == aws.StringValue(eniInfo.Attachment.InstanceId) {
-					return ctrl.Result{}, nil
-				}
-				err = r.detachENI(aws.StringValue(eniInfo.Attachment.AttachmentId))
-				if err != nil {
-					if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "InvalidAttachmentID.NotFound" {
-						err = nil
-					}
-				}
-				return ctrl.Result{RequeueAfter: 3 * time.Second}, err
 			}
+			return r.setState(ctx, &eni, awsv1alpha1.ENIStateDetaching)
+		case eniAttachmentWaitForDetach:
+			return r.setState(ctx, &eni, awsv1alpha1.ENIStateDetaching)
+		case eniAttachmentWaitForAttach:
+			return r.setState(ctx, &eni, awsv1alpha1.ENIStateAttaching)
+		case eniAttachmentRecordAttached:
+			eni.Status.Attachment = observedAttachment(eni.Spec.Attachment, eniInfo.Attachment)
+			return r.setState(ctx, &eni, awsv1alpha1.ENIStateAttached)
+		case eniAttachmentRecordAvailable:
+			eni.Status.Attachment = nil
+			return r.setState(ctx, &eni, awsv1alpha1.ENIStateAvailable)
 		}
-
-		eni.Status.Attachment = eni.Spec.Attachment
-		return ctrl.Result{}, r.Update(ctx, &eni)
+		if eni.Spec.Attachment == nil {
+			return ctrl.Result{}, r.reconcilePodNetworkCondition(ctx, &eni, "")
+		}
+		if err := r.reconcilePodNetworkCondition(ctx, &eni, eni.Spec.Attachment.PodName); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.reconcileAttachmentAttributes(ctx, &eni, eniInfo.Attachment)
 	} else if containsString(eni.ObjectMeta.Finalizers, finalizerName) {
 		if eni.Status.NetworkInterfaceID != "" {
-			resp, err := r.EC2.DescribeNetworkInterfaces(&ec2.DescribeNetworkInterfacesInput{
-				NetworkInterfaceIds: []*string{aws.String(eni.Status.NetworkInterfaceID)},
-			})
+			eniInfo, err := describeNetworkInterface(ctx, r.EC2, eni.Status.NetworkInterfaceID)
 			if err != nil {
-				if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() != "InvalidNetworkInterfaceID.NotFound" {
+				// only forget the ENI if it is known to be gone, to not leak it
+				if !isNotFound(err) {
 					return ctrl.Result{}, err
 				}
+			} else if isClaimPending(&eni, eniInfo.TagSet) {
+				// leave the ENI to the pool, which adopts it again if it was
+				// removed from it already
+				log.Info("releasing ENI claimed from pool", "networkInterfaceID", eni.Status.NetworkInterfaceID)
 			} else {
-				eniInfo := resp.NetworkInterfaces[0]
-				if eniInfo.Attachment != nil && aws.StringValue(eniInfo.Attachment.Status) == "attached" {
-					err := r.detachENI(aws.StringValue(eniInfo.Attachment.AttachmentId))
-					if err != nil {
-						if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() != "InvalidAttachmentID.NotFound" {
-							return ctrl.Result{}, err
+				if err := ensureOwnership(ctx, r.EC2, "ENI", eni.Status.NetworkInterfaceID, eniInfo.TagSet, &eni, r.ClusterName, r.AdoptUntagged, true, log); err != nil {
+					return ctrl.Result{}, err
+				}
+				if eniInfo.Attachment != nil {
+					switch aws.StringValue(eniInfo.Attachment.Status) {
+					case ec2.AttachmentStatusAttaching, ec2.AttachmentStatusAttached:
+						err := r.detachENI(aws.StringValue(eniInfo.Attachment.AttachmentId))
+						if err != nil {
+							if !isAWSErrorCode(err, "InvalidAttachmentID.NotFound") {
+								return ctrl.Result{}, err
+							}
 						}
+						eni.Status.Attachment = nil
+						return r.setState(ctx, &eni, awsv1alpha1.ENIStateDetaching)
+					case ec2.AttachmentStatusDetaching:
+						return ctrl.Result{RequeueAfter: eniPollIntervals[awsv1alpha1.ENIStateDetaching]}, nil
 					}
+				}
+				if eni.Status.State != awsv1alpha1.ENIStateDeleting {
 					eni.Status.Attachment = nil
-					return ctrl.Result{}, r.Update(ctx, &eni)
+					return r.setState(ctx, &eni, awsv1alpha1.ENIStateDeleting)
 				}
 				_, err = r.EC2.DeleteNetworkInterface(&ec2.DeleteNetworkInterfaceInput{
 					NetworkInterfaceId: aws.String(eni.Status.NetworkInterfaceID),
 				})
 				if err != nil {
-					if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() != "InvalidNetworkInterfaceID.NotFound" {
+					if !isAWSErrorCode(err, "InvalidNetworkInterfaceID.NotFound") {
 						return ctrl.Result{}, err
 					}
 				}
@@ -255,6 +384,179 @@ func (r *ENIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	return ctrl.Result{}, nil
 }
 
+// updateSyncedCondition records the outcome of a reconciliation in the Synced
+// condition of the ENI.
+func (r *ENIReconciler) updateSyncedCondition(ctx context.Context, key types.NamespacedName, reconcileErr error) error {
+	var eni awsv1alpha1.ENI
+	if err := r.Get(ctx, key, &eni); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !setSyncedCondition(&eni.Status.Conditions, eni.Generation, reconcileErr) {
+		return nil
+	}
+	// a conflicting update triggers another reconciliation anyway
+	return ignoreConflict(r.Update(ctx, &eni))
+}
+
+// withEC2Client returns a copy of the reconciler using the EC2 client of the
+// given AWSAccount and region, or the reconciler itself if neither is given.
+func (r *ENIReconciler) withEC2Client(ctx context.Context, accountName, region, namespace string) (*ENIReconciler, error) {
+	if accountName == "" && region == "" {
+		return r, nil
+	}
+	var account *awsv1alpha1.AWSAccount
+	if accountName != "" {
+		var err error
+		if account, err = getAWSAccount(ctx, r.Client, accountName, namespace); err != nil {
+			return nil, err
+		}
+	}
+	copy := *r
+	copy.EC2, copy.region = r.EC2Clients.Get(account, region)
+	return &copy, nil
+}
+
+// claimFromPool takes a pre-created ENI from the pool referenced by the ENI.
+// It returns false if the pool is empty.
+func (r *ENIReconciler) claimFromPool(ctx context.Context, eni *awsv1alpha1.ENI) (bool, error) {
+	pool, err := r.getPool(ctx, eni)
+	if err != nil {
+		return false, err
+	}
+	if len(pool.Status.NetworkInterfaceIDs) == 0 {
+		return false, nil
+	}
+	if r.DryRun {
+		return false, newPlannedClaim(eni, pool.Status.NetworkInterfaceIDs[0])
+	}
+
+	// recording the ENI in the status first prevents the pool from adopting
+	// it again once it is removed from the pool
+	eni.Status.NetworkInterfaceID = pool.Status.NetworkInterfaceIDs[0]
+	if err := r.Update(ctx, eni); err != nil {
+		return false, err
+	}
+	return true, r.completeClaim(ctx, eni, pool)
+}
+
+// completeClaim removes the ENI recorded in the status from the pool and
+// tags it as owned by the ENI instead of the pool. If the pool does not list
+// the ENI anymore and another ENI claimed it, or removing it from the pool
+// fails, e.g. because of a conflicting claim, the claim is released.
+func (r *ENIReconciler) completeClaim(ctx context.Context, eni *awsv1alpha1.ENI, pool *awsv1alpha1.ENIPool) error {
+	id := eni.Status.NetworkInterfaceID
+	if r.DryRun {
+		return newPlannedClaim(eni, id)
+	}
+	if pool == nil {
+		var err error
+		if pool, err = r.getPool(ctx, eni); err != nil {
+			return err
+		}
+	}
+
+	if containsString(pool.Status.NetworkInterfaceIDs, id) {
+		// removing the ENI from the pool fails on conflicting updates, so
+		// that it cannot be claimed twice
+		pool.Status.NetworkInterfaceIDs = removeString(pool.Status.NetworkInterfaceIDs, id)
+		pool.Status.Available = len(pool.Status.NetworkInterfaceIDs)
+		if err := r.Update(ctx, pool); err != nil {
+			return r.releaseClaim(ctx, eni, err)
+		}
+	} else {
+		claimedBy, err := r.getClaimingENI(ctx, eni, id)
+		if err != nil {
+			return err
+		}
+		if claimedBy != "" {
+			return r.releaseClaim(ctx, eni, fmt.Errorf("ENI %s of pool %s was claimed by %s", id, pool.Name, claimedBy))
+		}
+	}
+
+	// the ENI is owned by the pool until now
+	if _, err := r.EC2.CreateTagsWithContext(ctx, &ec2.CreateTagsInput{
+		Resources: []*string{aws.String(id)},
+		Tags:      convertMapToTags(ownershipTags(r.ClusterName, eni)),
+	}); err != nil {
+		return err
+	}
+
+	// prevent the pool from adopting the ENI again
+	if _, err := r.EC2.DeleteTagsWithContext(ctx, &ec2.DeleteTagsInput{
+		Resources: []*string{aws.String(id)},
+		Tags:      []*ec2.Tag{{Key: aws.String(eniPoolTagKey)}},
+	}); err != nil {
+		return err
+	}
+
+	eniInfo, err := describeNetworkInterface(ctx, r.EC2, id)
+	if err != nil {
+		return err
+	}
+	eni.Status.MacAddress = aws.StringValue(eniInfo.MacAddress)
+	eni.Status.PrivateIPAddresses = r.getPrivateIPAddresses(eniInfo.PrivateIpAddresses)
+	return nil
+}
+
+// newPlannedClaim describes claiming an ENI from the pool in dry-run mode,
+// which would update the status of the pool and the ENI.
+func newPlannedClaim(eni *awsv1alpha1.ENI, id string) error {
+	return newPlannedWrite("Update", map[string]string{
+		"eniPool":            eni.Namespace + "/" + eni.Spec.PoolName,
+		"eni":                client.ObjectKeyFromObject(eni).String(),
+		"networkInterfaceID": id,
+	})
+}
+
+// releaseClaim removes the ENI claimed from the pool from the status, and
+// returns the error causing it.
+func (r *ENIReconciler) releaseClaim(ctx context.Context, eni *awsv1alpha1.ENI, err error) error {
+	eni.Status.NetworkInterfaceID = ""
+	if updateErr := r.Update(ctx, eni); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// getClaimingENI returns the name of another ENI in the namespace that
+// claimed the pool ENI, if any.
+func (r *ENIReconciler) getClaimingENI(ctx context.Context, eni *awsv1alpha1.ENI, id string) (string, error) {
+	var enis awsv1alpha1.ENIList
+	if err := r.List(ctx, &enis, client.InNamespace(eni.Namespace)); err != nil {
+		return "", err
+	}
+	for _, other := range enis.Items {
+		if other.UID != eni.UID && other.Status.NetworkInterfaceID == id {
+			return other.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// isClaimPending returns whether the ENI recorded in the status is still
+// tagged as belonging to the pool referenced by the ENI, i.e. claiming it was
+// interrupted.
+func isClaimPending(eni *awsv1alpha1.ENI, tags []*ec2.Tag) bool {
+	return eni.Spec.PoolName != "" && getTag(tags, eniPoolTagKey) == eni.Namespace+"/"+eni.Spec.PoolName
+}
+
+func (r *ENIReconciler) getPool(ctx context.Context, eni *awsv1alpha1.ENI) (*awsv1alpha1.ENIPool, error) {
+	var pool awsv1alpha1.ENIPool
+	if err := r.Get(ctx, client.ObjectKey{
+		Namespace: eni.Namespace,
+		Name:      eni.Spec.PoolName,
+	}, &pool); err != nil {
+		return nil, err
+	}
+	if pool.Spec.SubnetID != eni.Spec.SubnetID {
+		return nil, fmt.Errorf("ENI pool %s is for subnet %s, not %s", pool.Name, pool.Spec.SubnetID, eni.Spec.SubnetID)
+	}
+	if pool.Spec.AWSAccountName != eni.Spec.AWSAccountName || pool.Spec.Region != eni.Spec.Region {
+		return nil, fmt.Errorf("ENI pool %s is not for the same AWS account and region as the ENI", pool.Name)
+	}
+	return &pool, nil
+}
+
 func (r *ENIReconciler) getPrivateIPAddresses(privateIPAddresses []*ec2.NetworkInterfacePrivateIpAddress) []string {
 	ret := []string{}
 	for _, ip := range privateIPAddresses {
@@ -342,19 +644,228 @@ func (r *ENIReconciler) getInstanceIDOfPod(namespace, podName string) (string, e
 	return aws.StringValue(eniInfo.Attachment.InstanceId), nil
 }
 
-func (r *ENIReconciler) attachENI(attachmentID, instanceID string) error {
-	resp, err := r.EC2.DescribeInstances(&ec2.DescribeInstancesInput{
-		InstanceIds: []*string{aws.String(instanceID)},
+func (r *ENIReconciler) getInstanceIDOfNode(nodeName string) (string, error) {
+	node := &corev1.Node{}
+	if err := r.NonCachingClient.Get(context.Background(), client.ObjectKey{
+		Name: nodeName,
+	}, node); err != nil {
+		return "", err
+	}
+
+	// the provider ID has the format aws:///<availability zone>/<instance ID>
+	providerID := node.Spec.ProviderID
+	instanceID := providerID[strings.LastIndex(providerID, "/")+1:]
+	if !strings.HasPrefix(providerID, "aws://") || !strings.HasPrefix(instanceID, "i-") {
+		return "", fmt.Errorf("cannot determine instance ID of node %s from provider ID %q", nodeName, providerID)
+	}
+
+	return instanceID, nil
+}
+
+func (r *ENIReconciler) getDesiredInstanceID(eni *awsv1alpha1.ENI) (string, error) {
+	attachment := eni.Spec.Attachment
+
+	modes := 0
+	if attachment.PodName != "" {
+		modes++
+	}
+	if attachment.NodeName != "" {
+		modes++
+	}
+	if attachment.InstanceID != "" {
+		modes++
+	}
+	if modes != 1 {
+		return "", fmt.Errorf("exactly one of podName, nodeName or instanceID needs to be given in attachment")
+	}
+
+	if attachment.InstanceID != "" {
+		return attachment.InstanceID, nil
+	}
+	if attachment.NodeName != "" {
+		return r.getInstanceIDOfNode(attachment.NodeName)
+	}
+	return r.getInstanceIDOfPod(eni.Namespace, attachment.PodName)
+}
+
+// setState updates the ENI with the given state and requeues it according to
+// the poll interval of that state.
+func (r *ENIReconciler) setState(ctx context.Context, eni *awsv1alpha1.ENI, state awsv1alpha1.ENIState) (ctrl.Result, error) {
+	eni.Status.State = state
+	if err := r.Update(ctx, eni); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: eniPollIntervals[state]}, nil
+}
+
+// observedAttachment returns the attachment to record in the status of an ENI
+// based on the desired attachment and the one reported by EC2.
+func observedAttachment(desired *awsv1alpha1.ENIAttachment, actual *ec2.NetworkInterfaceAttachment) *awsv1alpha1.ENIAttachment {
+	attachment := *desired
+	attachment.InstanceID = aws.StringValue(actual.InstanceId)
+	attachment.DeviceIndex = aws.Int64(aws.Int64Value(actual.DeviceIndex))
+	attachment.NetworkCardIndex = aws.Int64(aws.Int64Value(actual.NetworkCardIndex))
+	attachment.DeleteOnTermination = aws.Bool(aws.BoolValue(actual.DeleteOnTermination))
+	return &attachment
+}
+
+// isAttachedAsDesired checks whether an existing attachment matches the
+// desired instance and, if given, the desired device and network card index.
+func isAttachedAsDesired(actual *ec2.NetworkInterfaceAttachment, instanceID string, desired *awsv1alpha1.ENIAttachment) bool {
+	if aws.StringValue(actual.InstanceId) != instanceID {
+		return false
+	}
+	if desired.DeviceIndex != nil && *desired.DeviceIndex != aws.Int64Value(actual.DeviceIndex) {
+		return false
+	}
+	if desired.NetworkCardIndex != nil && *desired.NetworkCardIndex != aws.Int64Value(actual.NetworkCardIndex) {
+		return false
+	}
+	return true
+}
+
+// reconcileAttachmentAttributes reconciles the attributes of an existing
+// attachment and reflects them in the status of the ENI.
+func (r *ENIReconciler) reconcileAttachmentAttributes(ctx context.Context, eni *awsv1alpha1.ENI, attachment *ec2.NetworkInterfaceAttachment) error {
+	deleteOnTermination := eni.Spec.Attachment.DeleteOnTermination
+	if deleteOnTermination != nil && *deleteOnTermination != aws.BoolValue(attachment.DeleteOnTermination) {
+		_, err := r.EC2.ModifyNetworkInterfaceAttribute(&ec2.ModifyNetworkInterfaceAttributeInput{
+			NetworkInterfaceId: aws.String(eni.Status.NetworkInterfaceID),
+			Attachment: &ec2.NetworkInterfaceAttachmentChanges{
+				AttachmentId:        attachment.AttachmentId,
+				DeleteOnTermination: deleteOnTermination,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		attachment.DeleteOnTermination = deleteOnTermination
+	}
+
+	changed := false
+	if eni.Status.Attachment != nil {
+		observed := aws.BoolValue(attachment.DeleteOnTermination)
+		if eni.Status.Attachment.DeleteOnTermination == nil || *eni.Status.Attachment.DeleteOnTermination != observed {
+			eni.Status.Attachment.DeleteOnTermination = aws.Bool(observed)
+			changed = true
+		}
+	}
+	if eni.Status.Error != "" {
+		eni.Status.Error = ""
+		changed = true
+	}
+
+	if changed {
+		return r.Update(ctx, eni)
+	}
+	return nil
+}
+
+// getMaxNetworkInterfaces returns how many ENIs can be attached to the given
+// network card of an instance type.
+func (r *ENIReconciler) getMaxNetworkInterfaces(instanceType string, networkCardIndex int64) (int64, error) {
+	resp, err := r.EC2.DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{aws.String(instanceType)},
 	})
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if len(resp.InstanceTypes) == 0 || resp.InstanceTypes[0].NetworkInfo == nil {
+		return 0, fmt.Errorf("no network information found for instance type %s", instanceType)
+	}
+
+	networkInfo := resp.InstanceTypes[0].NetworkInfo
+	if len(networkInfo.NetworkCards) == 0 && networkCardIndex == 0 {
+		return aws.Int64Value(networkInfo.MaximumNetworkInterfaces), nil
+	}
+	for _, card := range networkInfo.NetworkCards {
+		if aws.Int64Value(card.NetworkCardIndex) == networkCardIndex {
+			return aws.Int64Value(card.MaximumNetworkInterfaces), nil
+		}
+	}
+	return 0, fmt.Errorf("instance type %s has no network card %d", instanceType, networkCardIndex)
+}
+
+// findFreeDeviceIndex returns the device index at which an ENI can be attached
+// to the given network card of an instance. If deviceIndex is given, it is
+// only checked for being free.
+func (r *ENIReconciler) findFreeDeviceIndex(instance *ec2.Instance, networkCardIndex int64, deviceIndex *int64) (int64, error) {
+	instanceID := aws.StringValue(instance.InstanceId)
+	instanceType := aws.StringValue(instance.InstanceType)
+
+	maxNetworkInterfaces, err := r.getMaxNetworkInterfaces(instanceType, networkCardIndex)
+	if err != nil {
+		return 0, err
 	}
 
-	_, err = r.EC2.AttachNetworkInterface(&ec2.AttachNetworkInterfaceInput{
-		NetworkInterfaceId: aws.String(attachmentID),
+	usedDeviceIndices := make(map[int64]bool)
+	for _, networkInterface := range instance.NetworkInterfaces {
+		if networkInterface.Attachment == nil || aws.Int64Value(networkInterface.Attachment.NetworkCardIndex) != networkCardIndex {
+			continue
+		}
+		usedDeviceIndices[aws.Int64Value(networkInterface.Attachment.DeviceIndex)] = true
+	}
+
+	if int64(len(usedDeviceIndices)) >= maxNetworkInterfaces {
+		return 0, fmt.Errorf("instance %s is out of ENI slots: %d of %d ENIs of instance type %s are attached to network card %d",
+			instanceID, len(usedDeviceIndices), maxNetworkInterfaces, instanceType, networkCardIndex)
+	}
+
+	if deviceIndex != nil {
+		if *deviceIndex >= maxNetworkInterfaces {
+			return 0, fmt.Errorf("device index %d is out of range, instance type %s supports %d ENIs on network card %d", *deviceIndex, instanceType, maxNetworkInterfaces, networkCardIndex)
+		}
+		if usedDeviceIndices[*deviceIndex] {
+			return 0, fmt.Errorf("device index %d is already in use on network card %d of instance %s", *deviceIndex, networkCardIndex, instanceID)
+		}
+		return *deviceIndex, nil
+	}
+
+	// device index 0 is reserved for the primary network interface
+	index := int64(1)
+	for usedDeviceIndices[index] {
+		index++
+	}
+	if index >= maxNetworkInterfaces {
+		return 0, fmt.Errorf("instance %s has no free device index below %d on network card %d", instanceID, maxNetworkInterfaces, networkCardIndex)
+	}
+	return index, nil
+}
+
+func (r *ENIReconciler) attachENI(networkInterfaceID, instanceID string, attachment *awsv1alpha1.ENIAttachment) (int64, int64, error) {
+	instance, err := describeInstance(context.Background(), r.EC2, instanceID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	networkCardIndex := aws.Int64Value(attachment.NetworkCardIndex)
+	deviceIndex, err := r.findFreeDeviceIndex(instance, networkCardIndex, attachment.DeviceIndex)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	input := &ec2.AttachNetworkInterfaceInput{
+		NetworkInterfaceId: aws.String(networkInterfaceID),
 		InstanceId:         aws.String(instanceID),
-		DeviceIndex:        aws.Int64(int64(len(resp.Reservations[0].Instances[0].NetworkInterfaces))),
-	})
+		DeviceIndex:        aws.Int64(deviceIndex),
+	}
+	if attachment.NetworkCardIndex != nil {
+		input.NetworkCardIndex = attachment.NetworkCardIndex
+	}
+
+	_, err = r.EC2.AttachNetworkInterface(input)
+	return deviceIndex, networkCardIndex, err
+}
+
+// setError records the given error in the status of the ENI and returns it,
+// so that reconciliation is retried.
+func (r *ENIReconciler) setError(ctx context.Context, eni *awsv1alpha1.ENI, err error) error {
+	if eni.Status.Error != err.Error() {
+		eni.Status.Error = err.Error()
+		if updateErr := r.Update(ctx, eni); updateErr != nil {
+			return updateErr
+		}
+	}
 	return err
 }
 
@@ -366,21 +877,21 @@ func (r *ENIReconciler) detachENI(attachmentID string) error {
 }
 
 func (r *ENIReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.subnetCIDRs = &sync.Map{}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&awsv1alpha1.ENI{}).
 		Complete(r)
 }
 
 // combineDefaultAndDefinedTags combines the default tags defined in the controller
-// with the tags defined in the ENI spec. Tags defined in the ENI spec override
-// default tags in case of key conflicts.
-func (r ENIReconciler) combineDefaultAndDefinedTags(eni *awsv1alpha1.ENI) []*ec2.Tag {
-	var tags []*ec2.Tag
-	tags = convertMapToTags(r.Tags)
+// with the tags defined in the ENI spec and the ownership tags. Tags defined in
+// the ENI spec override default tags in case of key conflicts.
+func (r *ENIReconciler) combineDefaultAndDefinedTags(eni *awsv1alpha1.ENI) []*ec2.Tag {
+	var specTags map[string]string
 	if eni.Spec.Tags != nil {
-		tags = append(tags, convertMapToTags(*eni.Spec.Tags)...)
+		specTags = *eni.Spec.Tags
 	}
-	return tags
+	return mergeTags(r.Tags, specTags, ownershipTags(r.ClusterName, eni))
 }
 
 func (r *ENIReconciler) reconcileTags(ctx context.Context, eni *awsv1alpha1.ENI, existingTags []*ec2.Tag) error {
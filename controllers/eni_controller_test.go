@@ -0,0 +1,176 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// fakeAttachmentEC2 implements the calls needed to pick the instance and
+// device index of an attachment.
+type fakeAttachmentEC2 struct {
+	ec2iface.EC2API
+	instanceTypes     map[string]*ec2.NetworkInfo
+	networkInterfaces []*ec2.NetworkInterface
+}
+
+func (f *fakeAttachmentEC2) DescribeInstanceTypes(input *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	output := &ec2.DescribeInstanceTypesOutput{}
+	for _, instanceType := range input.InstanceTypes {
+		if networkInfo, ok := f.instanceTypes[aws.StringValue(instanceType)]; ok {
+			output.InstanceTypes = append(output.InstanceTypes, &ec2.InstanceTypeInfo{InstanceType: instanceType, NetworkInfo: networkInfo})
+		}
+	}
+	return output, nil
+}
+
+func (f *fakeAttachmentEC2) DescribeNetworkInterfaces(input *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	ip := aws.StringValue(input.Filters[0].Values[0])
+	output := &ec2.DescribeNetworkInterfacesOutput{}
+	for _, networkInterface := range f.networkInterfaces {
+		if aws.StringValue(networkInterface.PrivateIpAddress) == ip {
+			output.NetworkInterfaces = append(output.NetworkInterfaces, networkInterface)
+		}
+	}
+	return output, nil
+}
+
+func TestFindFreeDeviceIndex(t *testing.T) {
+	r := &ENIReconciler{EC2: &fakeAttachmentEC2{instanceTypes: map[string]*ec2.NetworkInfo{
+		"m5.large": {MaximumNetworkInterfaces: aws.Int64(3)},
+		"p4d.24xlarge": {
+			MaximumNetworkInterfaces: aws.Int64(60),
+			NetworkCards: []*ec2.NetworkCardInfo{
+				{NetworkCardIndex: aws.Int64(0), MaximumNetworkInterfaces: aws.Int64(15)},
+				{NetworkCardIndex: aws.Int64(1), MaximumNetworkInterfaces: aws.Int64(2)},
+			},
+		},
+	}}}
+	attached := func(networkCardIndex int64, deviceIndices ...int64) []*ec2.InstanceNetworkInterface {
+		var networkInterfaces []*ec2.InstanceNetworkInterface
+		for _, deviceIndex := range deviceIndices {
+			networkInterfaces = append(networkInterfaces, &ec2.InstanceNetworkInterface{Attachment: &ec2.InstanceNetworkInterfaceAttachment{
+				DeviceIndex:      aws.Int64(deviceIndex),
+				NetworkCardIndex: aws.Int64(networkCardIndex),
+			}})
+		}
+		return networkInterfaces
+	}
+
+	tests := []struct {
+		name              string
+		instanceType      string
+		networkInterfaces []*ec2.InstanceNetworkInterface
+		networkCardIndex  int64
+		deviceIndex       *int64
+		want              int64
+		wantErr           bool
+	}{
+		{"first free index", "m5.large", attached(0, 0), 0, nil, 1, false},
+		{"gap", "m5.large", attached(0, 0, 2), 0, nil, 1, false},
+		{"last slot", "m5.large", attached(0, 0, 1), 0, nil, 2, false},
+		{"out of slots", "m5.large", attached(0, 0, 1, 2), 0, nil, 0, true},
+		{"free index beyond limit", "m5.large", attached(0, 1, 2), 0, nil, 0, true},
+		{"given index", "m5.large", attached(0, 0), 0, aws.Int64(2), 2, false},
+		{"given index in use", "m5.large", attached(0, 0, 2), 0, aws.Int64(2), 0, true},
+		{"given index beyond limit", "m5.large", attached(0, 0), 0, aws.Int64(3), 0, true},
+		{"other network card", "p4d.24xlarge", append(attached(0, 0, 1), attached(1, 0)...), 1, nil, 1, false},
+		{"other network card out of slots", "p4d.24xlarge", append(attached(0, 0), attached(1, 0, 1)...), 1, nil, 0, true},
+		{"unknown network card", "p4d.24xlarge", attached(0, 0), 2, nil, 0, true},
+		{"unknown instance type", "x1.unknown", attached(0, 0), 0, nil, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instance := &ec2.Instance{
+				InstanceId:        aws.String("i-1"),
+				InstanceType:      aws.String(test.instanceType),
+				NetworkInterfaces: test.networkInterfaces,
+			}
+			got, err := r.findFreeDeviceIndex(instance, test.networkCardIndex, test.deviceIndex)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("findFreeDeviceIndex() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetDesiredInstanceID(t *testing.T) {
+	node := func(name, providerID string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: corev1.NodeSpec{ProviderID: providerID}}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod"},
+		Status:     corev1.PodStatus{PodIP: "10.0.0.10"},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		node("node", "aws:///us-east-1a/i-0123456789abcdef0"),
+		node("kind-node", "kind://docker/kind/kind-node"),
+		node("fargate-node", "aws:///us-east-1a/fargate-ip-10-0-0-1"),
+		pod,
+	).Build()
+	r := &ENIReconciler{
+		NonCachingClient: k8sClient,
+		EC2: &fakeAttachmentEC2{networkInterfaces: []*ec2.NetworkInterface{{
+			PrivateIpAddress: aws.String("10.0.0.10"),
+			Attachment:       &ec2.NetworkInterfaceAttachment{InstanceId: aws.String("i-pod"), Status: aws.String(ec2.AttachmentStatusAttached)},
+		}}},
+	}
+
+	tests := []struct {
+		name       string
+		attachment awsv1alpha1.ENIAttachment
+		want       string
+		wantErr    bool
+	}{
+		{"instance", awsv1alpha1.ENIAttachment{InstanceID: "i-given"}, "i-given", false},
+		{"node", awsv1alpha1.ENIAttachment{NodeName: "node"}, "i-0123456789abcdef0", false},
+		{"node not on AWS", awsv1alpha1.ENIAttachment{NodeName: "kind-node"}, "", true},
+		{"Fargate node", awsv1alpha1.ENIAttachment{NodeName: "fargate-node"}, "", true},
+		{"missing node", awsv1alpha1.ENIAttachment{NodeName: "missing"}, "", true},
+		{"pod", awsv1alpha1.ENIAttachment{PodName: "pod"}, "i-pod", false},
+		{"none", awsv1alpha1.ENIAttachment{}, "", true},
+		{"node and instance", awsv1alpha1.ENIAttachment{NodeName: "node", InstanceID: "i-given"}, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eni := &awsv1alpha1.ENI{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "eni"},
+				Spec:       awsv1alpha1.ENISpec{Attachment: &test.attachment},
+			}
+			got, err := r.getDesiredInstanceID(eni)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("getDesiredInstanceID() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
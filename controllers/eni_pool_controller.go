@@ -0,0 +1,290 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+const (
+	// eniPoolTagKey marks ENIs that were pre-created for a pool and have not
+	// been claimed yet.
+	eniPoolTagKey = "aws.k8s.logmein.com/eni-pool"
+)
+
+// ENIPoolReconciler reconciles a ENIPool object
+type ENIPoolReconciler struct {
+	client.Client
+	Log        logr.Logger
+	EC2        ec2iface.EC2API
+	EC2Clients *EC2Clients
+	Tags       map[string]string
+
+	// ClusterName is tagged on created resources to mark them as owned by
+	// the cluster, see ownershipTags.
+	ClusterName string
+	// AdoptUntagged allows adopting resources that are not tagged as owned
+	// by any cluster, e.g. created by earlier versions of the operator.
+	AdoptUntagged bool
+
+	// region of EC2, set by withEC2Client
+	region string
+}
+
+// +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=enipools,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=enipools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=aws.k8s.logmein.com,resources=enis,verbs=get;list;watch
+
+func (r *ENIPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	result, err := r.reconcile(ctx, req)
+	if isDryRun(err) {
+		logPlannedAction(r.Log.WithValues("enipool", req.NamespacedName), err)
+		return ctrl.Result{RequeueAfter: dryRunRequeueInterval}, nil
+	}
+	return result, err
+}
+
+func (r *ENIPoolReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("enipool", req.NamespacedName)
+
+	var pool awsv1alpha1.ENIPool
+	if err := r.Get(ctx, req.NamespacedName, &pool); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	r, err := r.withEC2Client(ctx, pool.Spec.AWSAccountName, pool.Spec.Region, pool.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if pool.ObjectMeta.DeletionTimestamp.IsZero() {
+		if !containsString(pool.ObjectMeta.Finalizers, finalizerName) {
+			pool.ObjectMeta.Finalizers = append(pool.ObjectMeta.Finalizers, finalizerName)
+			return ctrl.Result{}, r.Update(ctx, &pool)
+		}
+
+		available, err := r.getAvailableENIs(ctx, &pool)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if len(available) < pool.Spec.Size {
+			log.Info("replenishing", "available", len(available), "size", pool.Spec.Size)
+			id, err := r.createENI(ctx, &pool)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, r.setENIs(ctx, &pool, append(available, id))
+		}
+
+		if len(available) > pool.Spec.Size {
+			id := available[len(available)-1]
+			log.Info("shrinking", "networkInterfaceID", id, "available", len(available), "size", pool.Spec.Size)
+			if err := r.deleteENI(ctx, id); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, r.setENIs(ctx, &pool, available[:len(available)-1])
+		}
+
+		// ENIs might have been deleted, attached or created outside of this reconciliation
+		if !equalStrings(pool.Status.NetworkInterfaceIDs, available) || pool.Status.Available != len(available) {
+			return ctrl.Result{}, r.setENIs(ctx, &pool, available)
+		}
+	} else if containsString(pool.ObjectMeta.Finalizers, finalizerName) {
+		available, err := r.getAvailableENIs(ctx, &pool)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		for _, id := range available {
+			if err := r.deleteENI(ctx, id); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		pool.Status = awsv1alpha1.ENIPoolStatus{}
+		pool.ObjectMeta.Finalizers = removeString(pool.ObjectMeta.Finalizers, finalizerName)
+		return ctrl.Result{}, r.Update(ctx, &pool)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// withEC2Client returns a copy of the reconciler using the EC2 client of the
+// given AWSAccount and region, or the reconciler itself if neither is given.
+func (r *ENIPoolReconciler) withEC2Client(ctx context.Context, accountName, region, namespace string) (*ENIPoolReconciler, error) {
+	if accountName == "" && region == "" {
+		return r, nil
+	}
+	var account *awsv1alpha1.AWSAccount
+	if accountName != "" {
+		var err error
+		if account, err = getAWSAccount(ctx, r.Client, accountName, namespace); err != nil {
+			return nil, err
+		}
+	}
+	copy := *r
+	copy.EC2, copy.region = r.EC2Clients.Get(account, region)
+	return &copy, nil
+}
+
+func (r *ENIPoolReconciler) setENIs(ctx context.Context, pool *awsv1alpha1.ENIPool, ids []string) error {
+	pool.Status.NetworkInterfaceIDs = ids
+	pool.Status.Available = len(ids)
+	return r.Update(ctx, pool)
+}
+
+// getAvailableENIs returns the unattached ENIs tagged as belonging to the
+// pool. ENIs listed in the status keep their order; ENIs missing from it,
+// e.g. because the status could not be updated after creating them, are
+// adopted, unless they are being claimed by an ENI. ENIs not tagged as owned
+// by the pool are ignored, unless they are listed in the status and not
+// tagged by any cluster, e.g. created by earlier versions of the operator.
+func (r *ENIPoolReconciler) getAvailableENIs(ctx context.Context, pool *awsv1alpha1.ENIPool) ([]string, error) {
+	log := r.Log.WithValues("enipool", client.ObjectKeyFromObject(pool))
+	resp, err := r.EC2.DescribeNetworkInterfacesWithContext(ctx, &ec2.DescribeNetworkInterfacesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:" + eniPoolTagKey),
+				Values: []*string{aws.String(eniPoolTagValue(pool))},
+			},
+			{
+				Name:   aws.String("status"),
+				Values: []*string{aws.String(ec2.NetworkInterfaceStatusAvailable)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make(map[string]bool)
+	for _, id := range pool.Status.NetworkInterfaceIDs {
+		recorded[id] = true
+	}
+
+	found := make(map[string]bool)
+	for _, networkInterface := range resp.NetworkInterfaces {
+		id := aws.StringValue(networkInterface.NetworkInterfaceId)
+		err := ensureOwnership(ctx, r.EC2, "ENI", id, networkInterface.TagSet, pool, r.ClusterName, r.AdoptUntagged, recorded[id], log)
+		var notOwned *notOwnedError
+		if errors.As(err, &notOwned) {
+			log.Info("ignoring ENI not owned by the pool", "networkInterfaceID", id)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		found[id] = true
+	}
+
+	var available []string
+	for _, id := range pool.Status.NetworkInterfaceIDs {
+		if found[id] {
+			available = append(available, id)
+			delete(found, id)
+		}
+	}
+	if len(found) > 0 {
+		// ENIs are recorded in the status of the claiming ENI before they are
+		// removed from the pool
+		var enis awsv1alpha1.ENIList
+		if err := r.List(ctx, &enis, client.InNamespace(pool.Namespace)); err != nil {
+			return nil, err
+		}
+		for _, eni := range enis.Items {
+			delete(found, eni.Status.NetworkInterfaceID)
+		}
+	}
+	for _, networkInterface := range resp.NetworkInterfaces {
+		if id := aws.StringValue(networkInterface.NetworkInterfaceId); found[id] {
+			available = append(available, id)
+		}
+	}
+	return available, nil
+}
+
+func eniPoolTagValue(pool *awsv1alpha1.ENIPool) string {
+	return pool.Namespace + "/" + pool.Name
+}
+
+func (r *ENIPoolReconciler) createENI(ctx context.Context, pool *awsv1alpha1.ENIPool) (string, error) {
+	// the available ENIs of the pool itself count towards the quota
+	if err := enforceENIPolicies(ctx, r.Client, pool.Namespace, getENIPoolPolicySubject(pool), ""); err != nil {
+		return "", err
+	}
+	if err := validateSubnetAndSecurityGroups(ctx, r.EC2, r.region, pool.Spec.SubnetID, pool.Spec.SecurityGroups); err != nil {
+		return "", err
+	}
+
+	input := &ec2.CreateNetworkInterfaceInput{
+		SubnetId:    aws.String(pool.Spec.SubnetID),
+		Groups:      aws.StringSlice(pool.Spec.SecurityGroups),
+		Description: aws.String(pool.Spec.Description),
+	}
+	if pool.Spec.SecondaryPrivateIPAddressCount > 0 {
+		input.SecondaryPrivateIpAddressCount = aws.Int64(pool.Spec.SecondaryPrivateIPAddressCount)
+	}
+
+	tags := ec2.TagSpecification{
+		ResourceType: aws.String("network-interface"),
+		Tags:         r.combineDefaultAndDefinedTags(pool),
+	}
+	input.TagSpecifications = []*ec2.TagSpecification{&tags}
+
+	resp, err := r.EC2.CreateNetworkInterfaceWithContext(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(resp.NetworkInterface.NetworkInterfaceId), nil
+}
+
+func (r *ENIPoolReconciler) deleteENI(ctx context.Context, id string) error {
+	_, err := r.EC2.DeleteNetworkInterfaceWithContext(ctx, &ec2.DeleteNetworkInterfaceInput{
+		NetworkInterfaceId: aws.String(id),
+	})
+	if isAWSErrorCode(err, "InvalidNetworkInterfaceID.NotFound") {
+		return nil
+	}
+	return err
+}
+
+// combineDefaultAndDefinedTags combines the default tags defined in the controller
+// with the tags defined in the ENIPool spec, the tag marking the ENI as
+// belonging to the pool and the ownership tags of the pool.
+func (r *ENIPoolReconciler) combineDefaultAndDefinedTags(pool *awsv1alpha1.ENIPool) []*ec2.Tag {
+	var specTags map[string]string
+	if pool.Spec.Tags != nil {
+		specTags = *pool.Spec.Tags
+	}
+	poolTags := ownershipTags(r.ClusterName, pool)
+	poolTags[eniPoolTagKey] = eniPoolTagValue(pool)
+	return mergeTags(r.Tags, specTags, poolTags)
+}
+
+func (r *ENIPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&awsv1alpha1.ENIPool{}).
+		Complete(r)
+}
@@ -0,0 +1,121 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// nextENIState returns the state an ENI in a transitional state changes to,
+// see the diagram at ENIStatus.State, given the status of its attachment as
+// reported by EC2 and whether all its private IP addresses are assigned. wait
+// is true while the ENI stays in its state and is polled. Other states are
+// returned as is, they are left when reconciling the attachment.
+func nextENIState(state awsv1alpha1.ENIState, attachmentStatus string, addressesAssigned bool) (next awsv1alpha1.ENIState, wait bool) {
+	switch state {
+	case "":
+		// ENI was created before states were introduced
+		if attachmentStatus == ec2.AttachmentStatusAttached {
+			return awsv1alpha1.ENIStateAttached, false
+		}
+		return awsv1alpha1.ENIStateAvailable, false
+	case awsv1alpha1.ENIStateCreating:
+		// wait until all requested private IP addresses are assigned
+		if !addressesAssigned {
+			return state, true
+		}
+		return awsv1alpha1.ENIStateAvailable, false
+	case awsv1alpha1.ENIStateAttaching:
+		switch attachmentStatus {
+		case ec2.AttachmentStatusAttaching:
+			return state, true
+		case ec2.AttachmentStatusAttached:
+			return awsv1alpha1.ENIStateAttached, false
+		default:
+			// attachment failed or was removed in the meantime
+			return awsv1alpha1.ENIStateAvailable, false
+		}
+	case awsv1alpha1.ENIStateDetaching:
+		switch attachmentStatus {
+		case ec2.AttachmentStatusDetaching:
+			return state, true
+		case ec2.AttachmentStatusAttaching, ec2.AttachmentStatusAttached:
+			// attached again in the meantime
+			return awsv1alpha1.ENIStateAttached, false
+		default:
+			return awsv1alpha1.ENIStateAvailable, false
+		}
+	}
+	return state, false
+}
+
+// eniAttachmentAction is what reconciling the attachment of an ENI does.
+type eniAttachmentAction string
+
+const (
+	// the ENI is attached as desired, or not attached and not to be
+	eniAttachmentKeep   eniAttachmentAction = "keep"
+	eniAttachmentAttach eniAttachmentAction = "attach"
+	eniAttachmentDetach eniAttachmentAction = "detach"
+	// a previous attachment is still being removed
+	eniAttachmentWaitForDetach eniAttachmentAction = "waitForDetach"
+	// the desired attachment is still being made
+	eniAttachmentWaitForAttach eniAttachmentAction = "waitForAttach"
+	// the ENI is attached as desired outside of the attaching state, e.g.
+	// by a previous version of the operator
+	eniAttachmentRecordAttached eniAttachmentAction = "recordAttached"
+	// the ENI is not attached, but not recorded as available
+	eniAttachmentRecordAvailable eniAttachmentAction = "recordAvailable"
+)
+
+// getENIAttachmentAction compares the attachment of an ENI as reported by EC2
+// to the desired one, on the instance with the given ID.
+func getENIAttachmentAction(eni *awsv1alpha1.ENI, actual *ec2.NetworkInterfaceAttachment, desiredInstanceID string) eniAttachmentAction {
+	attachmentStatus := ""
+	if actual != nil {
+		attachmentStatus = aws.StringValue(actual.Status)
+	}
+
+	if eni.Spec.Attachment == nil {
+		if attachmentStatus == ec2.AttachmentStatusAttached {
+			return eniAttachmentDetach
+		}
+		if eni.Status.State != awsv1alpha1.ENIStateAvailable || eni.Status.Attachment != nil {
+			return eniAttachmentRecordAvailable
+		}
+		return eniAttachmentKeep
+	}
+
+	if attachmentStatus == ec2.AttachmentStatusDetaching {
+		return eniAttachmentWaitForDetach
+	}
+	if actual == nil || attachmentStatus == ec2.AttachmentStatusDetached {
+		return eniAttachmentAttach
+	}
+	if !isAttachedAsDesired(actual, desiredInstanceID, eni.Spec.Attachment) {
+		return eniAttachmentDetach
+	}
+	if attachmentStatus == ec2.AttachmentStatusAttaching {
+		return eniAttachmentWaitForAttach
+	}
+	if eni.Status.State != awsv1alpha1.ENIStateAttached || eni.Status.Attachment == nil {
+		return eniAttachmentRecordAttached
+	}
+	return eniAttachmentKeep
+}
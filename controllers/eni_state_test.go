@@ -0,0 +1,157 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+func TestNextENIState(t *testing.T) {
+	type transition struct {
+		from              awsv1alpha1.ENIState
+		attachmentStatus  string
+		addressesAssigned bool
+	}
+	type result struct {
+		next awsv1alpha1.ENIState
+		wait bool
+	}
+	// transitions not listed keep the state without waiting
+	want := map[transition]result{}
+	for _, assigned := range []bool{false, true} {
+		for _, status := range []string{"", ec2.AttachmentStatusAttaching, ec2.AttachmentStatusDetaching, ec2.AttachmentStatusDetached} {
+			want[transition{"", status, assigned}] = result{awsv1alpha1.ENIStateAvailable, false}
+			want[transition{awsv1alpha1.ENIStateAttaching, status, assigned}] = result{awsv1alpha1.ENIStateAvailable, false}
+			want[transition{awsv1alpha1.ENIStateDetaching, status, assigned}] = result{awsv1alpha1.ENIStateAvailable, false}
+		}
+		want[transition{"", ec2.AttachmentStatusAttached, assigned}] = result{awsv1alpha1.ENIStateAttached, false}
+		want[transition{awsv1alpha1.ENIStateAttaching, ec2.AttachmentStatusAttaching, assigned}] = result{awsv1alpha1.ENIStateAttaching, true}
+		want[transition{awsv1alpha1.ENIStateAttaching, ec2.AttachmentStatusAttached, assigned}] = result{awsv1alpha1.ENIStateAttached, false}
+		want[transition{awsv1alpha1.ENIStateDetaching, ec2.AttachmentStatusDetaching, assigned}] = result{awsv1alpha1.ENIStateDetaching, true}
+		want[transition{awsv1alpha1.ENIStateDetaching, ec2.AttachmentStatusAttaching, assigned}] = result{awsv1alpha1.ENIStateAttached, false}
+		want[transition{awsv1alpha1.ENIStateDetaching, ec2.AttachmentStatusAttached, assigned}] = result{awsv1alpha1.ENIStateAttached, false}
+	}
+	for _, status := range []string{"", ec2.AttachmentStatusAttaching, ec2.AttachmentStatusAttached, ec2.AttachmentStatusDetaching, ec2.AttachmentStatusDetached} {
+		want[transition{awsv1alpha1.ENIStateCreating, status, false}] = result{awsv1alpha1.ENIStateCreating, true}
+		want[transition{awsv1alpha1.ENIStateCreating, status, true}] = result{awsv1alpha1.ENIStateAvailable, false}
+	}
+
+	states := []awsv1alpha1.ENIState{
+		"",
+		awsv1alpha1.ENIStateCreating,
+		awsv1alpha1.ENIStateAvailable,
+		awsv1alpha1.ENIStateAttaching,
+		awsv1alpha1.ENIStateAttached,
+		awsv1alpha1.ENIStateDetaching,
+		awsv1alpha1.ENIStateDeleting,
+	}
+	for _, from := range states {
+		for _, status := range []string{"", ec2.AttachmentStatusAttaching, ec2.AttachmentStatusAttached, ec2.AttachmentStatusDetaching, ec2.AttachmentStatusDetached} {
+			for _, assigned := range []bool{false, true} {
+				expected, ok := want[transition{from, status, assigned}]
+				if !ok {
+					expected = result{from, false}
+				}
+				next, wait := nextENIState(from, status, assigned)
+				if next != expected.next || wait != expected.wait {
+					t.Errorf("nextENIState(%q, %q, %t) = %q, %t, want %q, %t", from, status, assigned, next, wait, expected.next, expected.wait)
+				}
+			}
+		}
+	}
+}
+
+func TestIsAttachedAsDesired(t *testing.T) {
+	actual := &ec2.NetworkInterfaceAttachment{
+		InstanceId:       aws.String("i-1"),
+		DeviceIndex:      aws.Int64(2),
+		NetworkCardIndex: aws.Int64(0),
+	}
+
+	tests := []struct {
+		name       string
+		instanceID string
+		desired    awsv1alpha1.ENIAttachment
+		want       bool
+	}{
+		{"same instance", "i-1", awsv1alpha1.ENIAttachment{}, true},
+		{"other instance", "i-2", awsv1alpha1.ENIAttachment{}, false},
+		{"same device index", "i-1", awsv1alpha1.ENIAttachment{DeviceIndex: aws.Int64(2)}, true},
+		{"other device index", "i-1", awsv1alpha1.ENIAttachment{DeviceIndex: aws.Int64(1)}, false},
+		{"same network card", "i-1", awsv1alpha1.ENIAttachment{NetworkCardIndex: aws.Int64(0)}, true},
+		{"other network card", "i-1", awsv1alpha1.ENIAttachment{NetworkCardIndex: aws.Int64(1)}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isAttachedAsDesired(actual, test.instanceID, &test.desired); got != test.want {
+				t.Errorf("isAttachedAsDesired() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetENIAttachmentAction(t *testing.T) {
+	attachment := func(status string) *ec2.NetworkInterfaceAttachment {
+		return &ec2.NetworkInterfaceAttachment{
+			InstanceId:       aws.String("i-1"),
+			DeviceIndex:      aws.Int64(1),
+			NetworkCardIndex: aws.Int64(0),
+			Status:           aws.String(status),
+		}
+	}
+	recorded := &awsv1alpha1.ENIAttachment{InstanceID: "i-1"}
+
+	tests := []struct {
+		name      string
+		desired   *awsv1alpha1.ENIAttachment
+		state     awsv1alpha1.ENIState
+		recorded  *awsv1alpha1.ENIAttachment
+		actual    *ec2.NetworkInterfaceAttachment
+		desiredID string
+		want      eniAttachmentAction
+	}{
+		{"not attached, not desired", nil, awsv1alpha1.ENIStateAvailable, nil, nil, "", eniAttachmentKeep},
+		{"attached, not desired", nil, awsv1alpha1.ENIStateAttached, recorded, attachment(ec2.AttachmentStatusAttached), "", eniAttachmentDetach},
+		{"detached, still recorded", nil, awsv1alpha1.ENIStateAttached, recorded, attachment(ec2.AttachmentStatusDetached), "", eniAttachmentRecordAvailable},
+		{"not attached", &awsv1alpha1.ENIAttachment{InstanceID: "i-1"}, awsv1alpha1.ENIStateAvailable, nil, nil, "i-1", eniAttachmentAttach},
+		{"detached", &awsv1alpha1.ENIAttachment{InstanceID: "i-1"}, awsv1alpha1.ENIStateAvailable, nil, attachment(ec2.AttachmentStatusDetached), "i-1", eniAttachmentAttach},
+		{"previous attachment detaching", &awsv1alpha1.ENIAttachment{InstanceID: "i-2"}, awsv1alpha1.ENIStateAttached, recorded, attachment(ec2.AttachmentStatusDetaching), "i-2", eniAttachmentWaitForDetach},
+		{"attaching", &awsv1alpha1.ENIAttachment{InstanceID: "i-1"}, awsv1alpha1.ENIStateAvailable, nil, attachment(ec2.AttachmentStatusAttaching), "i-1", eniAttachmentWaitForAttach},
+		{"attached as desired", &awsv1alpha1.ENIAttachment{InstanceID: "i-1"}, awsv1alpha1.ENIStateAttached, recorded, attachment(ec2.AttachmentStatusAttached), "i-1", eniAttachmentKeep},
+		{"attached outside of the attaching state", &awsv1alpha1.ENIAttachment{InstanceID: "i-1"}, awsv1alpha1.ENIStateAvailable, nil, attachment(ec2.AttachmentStatusAttached), "i-1", eniAttachmentRecordAttached},
+		{"attached without recorded attachment", &awsv1alpha1.ENIAttachment{InstanceID: "i-1"}, awsv1alpha1.ENIStateAttached, nil, attachment(ec2.AttachmentStatusAttached), "i-1", eniAttachmentRecordAttached},
+		{"attached to other instance", &awsv1alpha1.ENIAttachment{InstanceID: "i-2"}, awsv1alpha1.ENIStateAttached, recorded, attachment(ec2.AttachmentStatusAttached), "i-2", eniAttachmentDetach},
+		{"attached at other device index", &awsv1alpha1.ENIAttachment{InstanceID: "i-1", DeviceIndex: aws.Int64(2)}, awsv1alpha1.ENIStateAttached, recorded, attachment(ec2.AttachmentStatusAttached), "i-1", eniAttachmentDetach},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eni := &awsv1alpha1.ENI{
+				Spec:   awsv1alpha1.ENISpec{Attachment: test.desired},
+				Status: awsv1alpha1.ENIStatus{State: test.state, Attachment: test.recorded},
+			}
+			if got := getENIAttachmentAction(eni, test.actual, test.desiredID); got != test.want {
+				t.Errorf("getENIAttachmentAction() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// handleExternalRelease moves an EIP whose allocation was released outside of
+// the operator to state lost, and reallocates or deletes it according to its
+// onExternalRelease policy. The public IP address is kept in the status, to
+// be recovered when reallocating.
+func (r *EIPReconciler) handleExternalRelease(ctx context.Context, eip *awsv1alpha1.EIP, log logr.Logger) error {
+	policy := eip.Spec.OnExternalRelease
+	if policy == "" {
+		policy = awsv1alpha1.ExternalReleaseMarkLost
+	}
+	log.Info("allocation ID not found; EIP was released outside of the operator", "allocationId", eip.Status.AllocationId, "onExternalRelease", policy)
+
+	// the annotations and DNS record refer to an address that is gone
+	if err := r.reconcilePodAnnotations(ctx, eip, eip.Status.Assignment, false); err != nil {
+		return err
+	}
+	if err := r.deleteDNSRecord(ctx, eip); err != nil {
+		return err
+	}
+
+	if err := setEIPState(eip, awsv1alpha1.EIPStateLost); err != nil {
+		return err
+	}
+	eip.Status.AllocationId = ""
+	eip.Status.AssociationId = ""
+	eip.Status.Assignment = nil
+	eip.Status.PodAnnotated = false
+	eip.Status.ReverseDNS = nil
+
+	switch policy {
+	case awsv1alpha1.ExternalReleaseReallocate:
+		if err := setEIPState(eip, awsv1alpha1.EIPStateAllocating); err != nil {
+			return err
+		}
+		return r.updateEIP(ctx, eip)
+	case awsv1alpha1.ExternalReleaseDelete:
+		if err := r.updateEIP(ctx, eip); err != nil {
+			return err
+		}
+		if r.DryRun {
+			return newPlannedWrite("Delete", client.ObjectKeyFromObject(eip).String())
+		}
+		log.Info("deleting")
+		return client.IgnoreNotFound(r.deleteEIP(ctx, eip))
+	default:
+		return r.updateEIP(ctx, eip)
+	}
+}
+
+// recoverAddress tries to allocate the public IP address an EIP had before it
+// was released outside of the operator. It returns the response and input of
+// the allocation, or no response and the original input if the address could
+// not be recovered.
+func (r *EIPReconciler) recoverAddress(ctx context.Context, input *ec2.AllocateAddressInput, eip *awsv1alpha1.EIP, log logr.Logger) (*ec2.AllocateAddressOutput, *ec2.AllocateAddressInput) {
+	recoveryInput := *input
+	recoveryInput.Address = aws.String(eip.Status.PublicIPAddress)
+	recoveryInput.PublicIpv4Pool = nil
+	if eip.Status.PublicIPv4Pool != "" {
+		recoveryInput.PublicIpv4Pool = aws.String(eip.Status.PublicIPv4Pool)
+	}
+
+	resp, err := r.EC2.AllocateAddressWithContext(ctx, &recoveryInput)
+	if err != nil {
+		log.Info("could not recover public IP address; allocating a new one", "publicIPAddress", eip.Status.PublicIPAddress, "error", err.Error())
+		return nil, input
+	}
+	log.Info("recovered public IP address", "publicIPAddress", eip.Status.PublicIPAddress)
+	return resp, &recoveryInput
+}
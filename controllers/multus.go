@@ -0,0 +1,298 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+const (
+	multusNetworksAnnotation = "k8s.v1.cni.cncf.io/networks"
+	multusCNIVersion         = "0.3.1"
+
+	// conditionTypeMultusNetworkRequested is the type of the condition
+	// telling whether the pod an ENI is attached to requests its
+	// NetworkAttachmentDefinition.
+	conditionTypeMultusNetworkRequested = "MultusNetworkRequested"
+
+	reasonNetworkRequested    = "Requested"
+	reasonNetworkNotRequested = "NotRequested"
+	reasonPodNotFound         = "PodNotFound"
+)
+
+var networkAttachmentDefinitionGVK = schema.GroupVersionKind{
+	Group:   "k8s.cni.cncf.io",
+	Version: "v1",
+	Kind:    "NetworkAttachmentDefinition",
+}
+
+// +kubebuilder:rbac:groups=k8s.cni.cncf.io,resources=network-attachment-definitions,verbs=get;create;update;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get
+
+type multusIPAMAddress struct {
+	Address string `json:"address"`
+}
+
+type multusIPAM struct {
+	Type      string              `json:"type"`
+	Addresses []multusIPAMAddress `json:"addresses"`
+}
+
+type multusConfig struct {
+	CNIVersion string     `json:"cniVersion"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	HWAddr     string     `json:"hwaddr,omitempty"`
+	Master     string     `json:"master,omitempty"`
+	Mode       string     `json:"mode,omitempty"`
+	IPAM       multusIPAM `json:"ipam"`
+}
+
+func (r *ENIReconciler) getSubnetCIDR(subnetID string) (string, error) {
+	if cidr, ok := r.subnetCIDRs.Load(subnetID); ok {
+		return cidr.(string), nil
+	}
+
+	resp, err := r.EC2.DescribeSubnets(&ec2.DescribeSubnetsInput{
+		SubnetIds: []*string{aws.String(subnetID)},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Subnets) == 0 {
+		return "", fmt.Errorf("subnet %s not found in %s", subnetID, describeRegion(r.region))
+	}
+
+	cidr := aws.StringValue(resp.Subnets[0].CidrBlock)
+	if !strings.Contains(cidr, "/") {
+		// e.g. IPv6-only subnets
+		return "", fmt.Errorf("subnet %s has no IPv4 CIDR block", subnetID)
+	}
+	r.subnetCIDRs.Store(subnetID, cidr)
+	return cidr, nil
+}
+
+// getMultusConfig returns the CNI configuration that moves the ENI into the
+// network namespace of a pod and configures its private IP addresses there.
+func (r *ENIReconciler) getMultusConfig(eni *awsv1alpha1.ENI) (string, error) {
+	cidr, err := r.getSubnetCIDR(eni.Spec.SubnetID)
+	if err != nil {
+		return "", err
+	}
+	slash := strings.Index(cidr, "/")
+	if slash < 0 {
+		return "", fmt.Errorf("invalid CIDR block %q of subnet %s", cidr, eni.Spec.SubnetID)
+	}
+	prefixLength := cidr[slash:]
+
+	config := multusConfig{
+		CNIVersion: multusCNIVersion,
+		Name:       eni.Name,
+		Type:       eni.Spec.Multus.Type,
+		IPAM: multusIPAM{
+			Type: "static",
+		},
+	}
+	for _, ip := range eni.Status.PrivateIPAddresses {
+		config.IPAM.Addresses = append(config.IPAM.Addresses, multusIPAMAddress{Address: ip + prefixLength})
+	}
+
+	switch config.Type {
+	case "", "host-device":
+		config.Type = "host-device"
+		config.HWAddr = eni.Status.MacAddress
+	case "ipvlan":
+		if eni.Spec.Multus.Master == "" {
+			return "", fmt.Errorf("multus.master needs to be given for type ipvlan")
+		}
+		config.Master = eni.Spec.Multus.Master
+		config.Mode = "l2"
+	default:
+		return "", fmt.Errorf("unsupported multus type %q", config.Type)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// reconcileNetworkAttachmentDefinition creates, updates or deletes the
+// NetworkAttachmentDefinition of an ENI. It is owned by the ENI and thus
+// garbage collected together with it.
+func (r *ENIReconciler) reconcileNetworkAttachmentDefinition(ctx context.Context, eni *awsv1alpha1.ENI) error {
+	nad := &unstructured.Unstructured{}
+	nad.SetGroupVersionKind(networkAttachmentDefinitionGVK)
+	err := r.NonCachingClient.Get(ctx, client.ObjectKey{
+		Namespace: eni.Namespace,
+		Name:      eni.Name,
+	}, nad)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if eni.Spec.Multus == nil {
+		if exists && metav1.IsControlledBy(nad, eni) {
+			if r.DryRun {
+				return newPlannedWrite("Delete", map[string]interface{}{
+					"networkAttachmentDefinition": client.ObjectKeyFromObject(nad).String(),
+				})
+			}
+			return client.IgnoreNotFound(r.NonCachingClient.Delete(ctx, nad))
+		}
+		return nil
+	}
+
+	config, err := r.getMultusConfig(eni)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		nad.SetNamespace(eni.Namespace)
+		nad.SetName(eni.Name)
+		nad.SetOwnerReferences([]metav1.OwnerReference{
+			*metav1.NewControllerRef(eni, awsv1alpha1.GroupVersion.WithKind("ENI")),
+		})
+		if err := unstructured.SetNestedField(nad.Object, config, "spec", "config"); err != nil {
+			return err
+		}
+		if r.DryRun {
+			return newPlannedWrite("Create", map[string]interface{}{
+				"networkAttachmentDefinition": client.ObjectKeyFromObject(nad).String(),
+				"config":                      config,
+			})
+		}
+		return r.NonCachingClient.Create(ctx, nad)
+	}
+
+	if !metav1.IsControlledBy(nad, eni) {
+		return fmt.Errorf("NetworkAttachmentDefinition %s/%s exists and is not owned by this ENI", eni.Namespace, eni.Name)
+	}
+	if existingConfig, _, _ := unstructured.NestedString(nad.Object, "spec", "config"); existingConfig == config {
+		return nil
+	}
+	if err := unstructured.SetNestedField(nad.Object, config, "spec", "config"); err != nil {
+		return err
+	}
+	if r.DryRun {
+		return newPlannedWrite("Update", map[string]interface{}{
+			"networkAttachmentDefinition": client.ObjectKeyFromObject(nad).String(),
+			"config":                      config,
+		})
+	}
+	return r.NonCachingClient.Update(ctx, nad)
+}
+
+// podRequestsNetwork returns whether the Multus networks annotation of a pod
+// lists the NetworkAttachmentDefinition of the ENI. Both the comma separated
+// and the JSON format of the annotation are supported.
+func podRequestsNetwork(pod *corev1.Pod, eni *awsv1alpha1.ENI) (bool, error) {
+	value := strings.TrimSpace(pod.Annotations[multusNetworksAnnotation])
+	qualifiedName := eni.Namespace + "/" + eni.Name
+
+	if strings.HasPrefix(value, "[") {
+		var networks []map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &networks); err != nil {
+			return false, fmt.Errorf("cannot parse annotation %s of pod %s: %w", multusNetworksAnnotation, pod.Name, err)
+		}
+		for _, network := range networks {
+			namespace, _ := network["namespace"].(string)
+			if network["name"] == eni.Name && (namespace == "" || namespace == eni.Namespace) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	for _, network := range strings.Split(value, ",") {
+		// strip the interface name, e.g. "namespace/name@eth1"
+		name := strings.SplitN(strings.TrimSpace(network), "@", 2)[0]
+		if name == eni.Name || name == qualifiedName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reconcilePodNetworkCondition sets the MultusNetworkRequested condition of
+// an ENI according to whether the pod it is attached to requests its
+// NetworkAttachmentDefinition, or removes it if there is no such pod.
+//
+// The operator does not add the network to the pod: Multus only evaluates the
+// annotation when the sandbox of a pod is created, which happens before the
+// ENI is attached. Pods thus need to request the network in their template;
+// creating their sandbox fails and is retried until the ENI is attached.
+func (r *ENIReconciler) reconcilePodNetworkCondition(ctx context.Context, eni *awsv1alpha1.ENI, podName string) error {
+	if podName == "" || eni.Spec.Multus == nil {
+		if meta.FindStatusCondition(eni.Status.Conditions, conditionTypeMultusNetworkRequested) == nil {
+			return nil
+		}
+		meta.RemoveStatusCondition(&eni.Status.Conditions, conditionTypeMultusNetworkRequested)
+		return r.Update(ctx, eni)
+	}
+
+	condition := metav1.Condition{
+		Type:               conditionTypeMultusNetworkRequested,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonNetworkRequested,
+		ObservedGeneration: eni.Generation,
+	}
+	pod := &corev1.Pod{}
+	if err := r.NonCachingClient.Get(ctx, client.ObjectKey{
+		Namespace: eni.Namespace,
+		Name:      podName,
+	}, pod); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonPodNotFound
+		condition.Message = fmt.Sprintf("pod %s not found", podName)
+	} else if requested, err := podRequestsNetwork(pod, eni); err != nil || !requested {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonNetworkNotRequested
+		condition.Message = fmt.Sprintf("pod %s does not request network %s/%s in annotation %s; add it to the pod template and restart the pod", podName, eni.Namespace, eni.Name, multusNetworksAnnotation)
+		if err != nil {
+			condition.Message = err.Error()
+		}
+	}
+
+	if existing := meta.FindStatusCondition(eni.Status.Conditions, conditionTypeMultusNetworkRequested); existing != nil &&
+		existing.Status == condition.Status && existing.Reason == condition.Reason &&
+		existing.Message == condition.Message && existing.ObservedGeneration == condition.ObservedGeneration {
+		return nil
+	}
+	meta.SetStatusCondition(&eni.Status.Conditions, condition)
+	return r.Update(ctx, eni)
+}
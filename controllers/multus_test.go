@@ -0,0 +1,161 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+func TestPodRequestsNetwork(t *testing.T) {
+	eni := &awsv1alpha1.ENI{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "my-eni"}}
+
+	tests := []struct {
+		name       string
+		annotation string
+		want       bool
+		wantErr    bool
+	}{
+		{"empty", "", false, false},
+		{"other network", "ns/other", false, false},
+		{"qualified name", "ns/other, ns/my-eni", true, false},
+		{"name with interface", "other,my-eni@eth1", true, false},
+		{"other namespace", "other-ns/my-eni", false, false},
+		{"JSON", `[{"name":"other"},{"name":"my-eni","namespace":"ns"}]`, true, false},
+		{"JSON without namespace", `[{"name":"my-eni"}]`, true, false},
+		{"JSON with other namespace", `[{"name":"my-eni","namespace":"other-ns"}]`, false, false},
+		{"invalid JSON", `[{"name":`, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &corev1.Pod{}
+			if test.annotation != "" {
+				pod.Annotations = map[string]string{multusNetworksAnnotation: test.annotation}
+			}
+
+			got, err := podRequestsNetwork(pod, eni)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("podRequestsNetwork() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReconcilePodNetworkCondition(t *testing.T) {
+	requesting := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "ns",
+		Name:        "requesting",
+		Annotations: map[string]string{multusNetworksAnnotation: "my-eni"},
+	}}
+	other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "other"}}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := awsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		podName    string
+		wantReason string
+	}{
+		{"requested", "requesting", reasonNetworkRequested},
+		{"not requested", "other", reasonNetworkNotRequested},
+		{"pod not found", "missing", reasonPodNotFound},
+		{"no pod", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eni := &awsv1alpha1.ENI{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "my-eni"},
+				Spec:       awsv1alpha1.ENISpec{Multus: &awsv1alpha1.ENIMultus{}},
+			}
+			meta.SetStatusCondition(&eni.Status.Conditions, metav1.Condition{Type: conditionTypeMultusNetworkRequested, Status: metav1.ConditionUnknown, Reason: "Stale"})
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(eni, requesting, other).Build()
+			r := &ENIReconciler{Client: k8sClient, NonCachingClient: k8sClient}
+
+			if err := r.reconcilePodNetworkCondition(context.Background(), eni, test.podName); err != nil {
+				t.Fatal(err)
+			}
+			condition := meta.FindStatusCondition(eni.Status.Conditions, conditionTypeMultusNetworkRequested)
+			if test.wantReason == "" {
+				if condition != nil {
+					t.Errorf("got condition %+v", condition)
+				}
+				return
+			}
+			if condition == nil || condition.Reason != test.wantReason {
+				t.Errorf("got condition %+v, want reason %s", condition, test.wantReason)
+			}
+		})
+	}
+}
+
+type fakeSubnets struct {
+	ec2iface.EC2API
+	subnet *ec2.Subnet
+}
+
+func (f *fakeSubnets) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	return &ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{f.subnet}}, nil
+}
+
+func TestGetMultusConfigSubnetCIDR(t *testing.T) {
+	eni := &awsv1alpha1.ENI{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-eni"},
+		Spec: awsv1alpha1.ENISpec{
+			SubnetID: "subnet-1",
+			Multus:   &awsv1alpha1.ENIMultus{Type: "host-device"},
+		},
+		Status: awsv1alpha1.ENIStatus{PrivateIPAddresses: []string{"10.0.0.5"}},
+	}
+
+	r := &ENIReconciler{EC2: &fakeSubnets{subnet: &ec2.Subnet{SubnetId: aws.String("subnet-1"), CidrBlock: aws.String("10.0.0.0/24")}}, subnetCIDRs: &sync.Map{}}
+	config, err := r.getMultusConfig(eni)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(config, `"address":"10.0.0.5/24"`) {
+		t.Errorf("got config %s", config)
+	}
+
+	// IPv6-only subnets have no IPv4 CIDR block
+	r = &ENIReconciler{EC2: &fakeSubnets{subnet: &ec2.Subnet{SubnetId: aws.String("subnet-1")}}, subnetCIDRs: &sync.Map{}}
+	if _, err := r.getMultusConfig(eni); err == nil {
+		t.Error("expected error for subnet without IPv4 CIDR block")
+	}
+}
@@ -0,0 +1,106 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// detectNetworkBorderGroup returns the network border group of the zone the
+// assignment target of an EIP is in: the zone of the node for pods and nodes,
+// the zone of the ENI otherwise.
+func (r *EIPReconciler) detectNetworkBorderGroup(ctx context.Context, eip *awsv1alpha1.EIP) (string, error) {
+	assignment := eip.Spec.Assignment
+	if assignment == nil || !hasAssignmentTarget(assignment) {
+		return "", fmt.Errorf("networkBorderGroup %s requires an assignment", awsv1alpha1.NetworkBorderGroupAuto)
+	}
+
+	nodeName := assignment.NodeName
+	if assignment.PodName != "" {
+		namespace := eip.Namespace
+		if assignment.Namespace != "" {
+			namespace = assignment.Namespace
+		}
+		pod := &corev1.Pod{}
+		if err := r.NonCachingClient.Get(ctx, client.ObjectKey{
+			Namespace: namespace,
+			Name:      assignment.PodName,
+		}, pod); err != nil {
+			return "", err
+		}
+		if pod.Spec.NodeName == "" {
+			return "", fmt.Errorf("pod %s is not scheduled yet", pod.Name)
+		}
+		nodeName = pod.Spec.NodeName
+	}
+
+	if nodeName != "" {
+		node := &corev1.Node{}
+		if err := r.NonCachingClient.Get(ctx, client.ObjectKey{
+			Name: nodeName,
+		}, node); err != nil {
+			return "", err
+		}
+		zone := node.Labels[corev1.LabelTopologyZone]
+		if zone == "" {
+			zone = node.Labels[corev1.LabelFailureDomainBetaZone]
+		}
+		if zone == "" {
+			return "", fmt.Errorf("node %s has no zone label", nodeName)
+		}
+		return r.getNetworkBorderGroup(ctx, zone)
+	}
+
+	eni, _, err := r.getAssignmentTarget(ctx, eip)
+	if err != nil {
+		return "", err
+	}
+	return r.getENINetworkBorderGroup(ctx, eni)
+}
+
+// getENINetworkBorderGroup returns the network border group of the zone an
+// ENI is in.
+func (r *EIPReconciler) getENINetworkBorderGroup(ctx context.Context, networkInterfaceID string) (string, error) {
+	eniInfo, err := describeNetworkInterface(ctx, r.EC2, networkInterfaceID)
+	if err != nil {
+		return "", err
+	}
+	return r.getNetworkBorderGroup(ctx, aws.StringValue(eniInfo.AvailabilityZone))
+}
+
+// getNetworkBorderGroup returns the network border group of an availability,
+// Local or Wavelength Zone.
+func (r *EIPReconciler) getNetworkBorderGroup(ctx context.Context, zone string) (string, error) {
+	resp, err := r.EC2.DescribeAvailabilityZonesWithContext(ctx, &ec2.DescribeAvailabilityZonesInput{
+		AllAvailabilityZones: aws.Bool(true),
+		ZoneNames:            []*string{aws.String(zone)},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.AvailabilityZones) == 0 {
+		return "", fmt.Errorf("zone %s not found in %s", zone, describeRegion(r.region))
+	}
+	return aws.StringValue(resp.AvailabilityZones[0].NetworkBorderGroup), nil
+}
@@ -0,0 +1,127 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Tags marking EIPs and ENIs as owned by an object in a cluster. They are set
+// when creating resources, so that IAM policies can restrict the operator to
+// its own resources with aws:RequestTag and aws:ResourceTag conditions.
+const (
+	clusterTagKey   = "aws.k8s.logmein.com/cluster"
+	namespaceTagKey = "aws.k8s.logmein.com/namespace"
+	nameTagKey      = "aws.k8s.logmein.com/name"
+	uidTagKey       = "aws.k8s.logmein.com/uid"
+)
+
+// adoptAnnotation allows an object to take over an existing resource that is
+// not tagged as owned by it, by tagging it.
+const adoptAnnotation = "aws.k8s.logmein.com/adopt"
+
+// notOwnedError is returned instead of changing a resource that is not
+// tagged as owned by the object.
+type notOwnedError struct {
+	resource string
+	id       string
+}
+
+func (e *notOwnedError) Error() string {
+	return fmt.Sprintf("%s %s is not tagged as owned by this object; annotate it with %s=true to adopt it", e.resource, e.id, adoptAnnotation)
+}
+
+// ownershipTags returns the tags marking a resource as owned by the object.
+// The namespace tag is left out for cluster-scoped objects.
+func ownershipTags(clusterName string, obj metav1.Object) map[string]string {
+	tags := map[string]string{
+		clusterTagKey: clusterName,
+		nameTagKey:    obj.GetName(),
+		uidTagKey:     string(obj.GetUID()),
+	}
+	if obj.GetNamespace() != "" {
+		tags[namespaceTagKey] = obj.GetNamespace()
+	}
+	return tags
+}
+
+// mergeTags merges maps of tags into a list of tags. Later maps take
+// precedence.
+func mergeTags(tagMaps ...map[string]string) []*ec2.Tag {
+	merged := make(map[string]string)
+	for _, tagMap := range tagMaps {
+		for k, v := range tagMap {
+			merged[k] = v
+		}
+	}
+	return convertMapToTags(merged)
+}
+
+// isOwned returns whether the tags of a resource mark it as owned by the
+// object the ownership tags are for.
+func isOwned(tags []*ec2.Tag, ownership map[string]string) bool {
+	return getTag(tags, clusterTagKey) == ownership[clusterTagKey] &&
+		getTag(tags, uidTagKey) == ownership[uidTagKey]
+}
+
+// mayAdopt returns whether an object may adopt a resource that is not tagged
+// as owned by it: always if it is annotated, and if the resource is not owned
+// by any cluster if it is recorded in the status of the object, e.g. created
+// by earlier versions of the operator, or if adoptUntagged is set.
+func mayAdopt(obj metav1.Object, tags []*ec2.Tag, adoptUntagged, recorded bool) bool {
+	if obj.GetAnnotations()[adoptAnnotation] == "true" {
+		return true
+	}
+	return (adoptUntagged || recorded) && getTag(tags, clusterTagKey) == ""
+}
+
+func getTag(tags []*ec2.Tag, key string) string {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == key {
+			return aws.StringValue(tag.Value)
+		}
+	}
+	return ""
+}
+
+// ensureOwnership checks that a resource is tagged as owned by the object
+// before it is changed, adopting it if allowed. recorded is whether the
+// resource is recorded in the status of the object. It returns a
+// notOwnedError otherwise.
+func ensureOwnership(ctx context.Context, ec2Client ec2iface.EC2API, resource, id string, tags []*ec2.Tag,
+	obj metav1.Object, clusterName string, adoptUntagged, recorded bool, log logr.Logger) error {
+	ownership := ownershipTags(clusterName, obj)
+	if isOwned(tags, ownership) {
+		return nil
+	}
+	if !mayAdopt(obj, tags, adoptUntagged, recorded) {
+		return &notOwnedError{resource: resource, id: id}
+	}
+
+	log.Info("adopting", "resource", resource, "id", id, "previousCluster", getTag(tags, clusterTagKey), "previousUID", getTag(tags, uidTagKey))
+	_, err := ec2Client.CreateTagsWithContext(ctx, &ec2.CreateTagsInput{
+		Resources: []*string{aws.String(id)},
+		Tags:      convertMapToTags(ownership),
+	})
+	return err
+}
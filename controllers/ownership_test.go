@@ -0,0 +1,111 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+type fakeTagger struct {
+	ec2iface.EC2API
+	tags []*ec2.Tag
+}
+
+func (f *fakeTagger) CreateTagsWithContext(ctx aws.Context, input *ec2.CreateTagsInput, opts ...request.Option) (*ec2.CreateTagsOutput, error) {
+	f.tags = input.Tags
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func TestOwnershipTags(t *testing.T) {
+	eip := &awsv1alpha1.EIP{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-eip", UID: "1234"}}
+	tags := ownershipTags("prod", eip)
+	if len(tags) != 4 || tags[clusterTagKey] != "prod" || tags[namespaceTagKey] != "default" || tags[nameTagKey] != "my-eip" || tags[uidTagKey] != "1234" {
+		t.Errorf("got %v", tags)
+	}
+
+	clusterEIP := &awsv1alpha1.EIP{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster-eip", UID: "5678"}}
+	if _, ok := ownershipTags("prod", clusterEIP)[namespaceTagKey]; ok {
+		t.Error("namespace tagged for cluster-scoped object")
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	tags := mergeTags(
+		map[string]string{"team": "default", "env": "prod"},
+		nil,
+		map[string]string{"team": "network"},
+	)
+	if len(tags) != 2 || getTag(tags, "team") != "network" || getTag(tags, "env") != "prod" {
+		t.Errorf("got %v", tags)
+	}
+}
+
+func TestEnsureOwnership(t *testing.T) {
+	owner := &awsv1alpha1.ENI{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-eni", UID: "1234"}}
+	adopting := owner.DeepCopy()
+	adopting.Annotations = map[string]string{adoptAnnotation: "true"}
+
+	owned := convertMapToTags(ownershipTags("prod", owner))
+	otherCluster := convertMapToTags(ownershipTags("staging", owner))
+	otherObject := mergeTags(ownershipTags("prod", owner), map[string]string{uidTagKey: "5678"})
+	untagged := []*ec2.Tag{{Key: aws.String("team"), Value: aws.String("network")}}
+
+	for _, test := range []struct {
+		name          string
+		obj           *awsv1alpha1.ENI
+		tags          []*ec2.Tag
+		adoptUntagged bool
+		recorded      bool
+		wantAdopted   bool
+		wantNotOwned  bool
+	}{
+		{name: "owned", obj: owner, tags: owned},
+		{name: "untagged", obj: owner, tags: untagged, wantNotOwned: true},
+		{name: "untagged, adopting untagged", obj: owner, tags: untagged, adoptUntagged: true, wantAdopted: true},
+		{name: "untagged, recorded", obj: owner, tags: untagged, recorded: true, wantAdopted: true},
+		{name: "other cluster, adopting untagged", obj: owner, tags: otherCluster, adoptUntagged: true, wantNotOwned: true},
+		{name: "other cluster, recorded", obj: owner, tags: otherCluster, recorded: true, wantNotOwned: true},
+		{name: "other object", obj: owner, tags: otherObject, wantNotOwned: true},
+		{name: "other object, annotated", obj: adopting, tags: otherObject, wantAdopted: true},
+		{name: "other cluster, annotated", obj: adopting, tags: otherCluster, wantAdopted: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ec2Client := &fakeTagger{}
+			err := ensureOwnership(context.Background(), ec2Client, "ENI", "eni-1", test.tags, test.obj, "prod", test.adoptUntagged, test.recorded, logr.Discard())
+			var notOwned *notOwnedError
+			if errors.As(err, &notOwned) != test.wantNotOwned {
+				t.Errorf("got error %v", err)
+			}
+			if adopted := ec2Client.tags != nil; adopted != test.wantAdopted {
+				t.Errorf("adopted: got %t, want %t", adopted, test.wantAdopted)
+			}
+			if test.wantAdopted && !isOwned(ec2Client.tags, ownershipTags("prod", test.obj)) {
+				t.Errorf("tagged with %v", ec2Client.tags)
+			}
+		})
+	}
+}
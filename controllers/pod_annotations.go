@@ -0,0 +1,97 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+const (
+	// podPublicIPAnnotation holds the public IP address of the EIP assigned
+	// to a pod, or its carrier IP address in Wavelength Zones.
+	podPublicIPAnnotation = "aws.k8s.logmein.com/public-ip"
+	// podAllocationIDAnnotation holds the allocation ID of the EIP assigned
+	// to a pod.
+	podAllocationIDAnnotation = "aws.k8s.logmein.com/allocation-id"
+)
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;patch
+
+// reconcilePodAnnotations adds the annotations describing the EIP to the pod
+// of an assignment, or removes them from it. Annotations of another EIP are
+// left alone.
+func (r *EIPReconciler) reconcilePodAnnotations(ctx context.Context, eip *awsv1alpha1.EIP, assignment *awsv1alpha1.EIPAssignment, add bool) error {
+	if assignment == nil || assignment.PodName == "" {
+		return nil
+	}
+
+	namespace := eip.Namespace
+	if assignment.Namespace != "" {
+		namespace = assignment.Namespace
+	}
+	pod := &corev1.Pod{}
+	if err := r.NonCachingClient.Get(ctx, client.ObjectKey{
+		Namespace: namespace,
+		Name:      assignment.PodName,
+	}, pod); err != nil {
+		// a pod that is gone needs no annotations
+		return client.IgnoreNotFound(err)
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if !updatePodAnnotations(pod, eip, add) {
+		return nil
+	}
+	if r.DryRun {
+		return newPlannedWrite("Patch", map[string]interface{}{
+			"pod":         client.ObjectKeyFromObject(pod).String(),
+			"annotations": pod.Annotations,
+		})
+	}
+	return r.NonCachingClient.Patch(ctx, pod, patch)
+}
+
+// updatePodAnnotations adds or removes the annotations describing the EIP and
+// returns whether the pod changed.
+func updatePodAnnotations(pod *corev1.Pod, eip *awsv1alpha1.EIP, add bool) bool {
+	if !add {
+		if pod.Annotations[podAllocationIDAnnotation] != eip.Status.AllocationId {
+			return false
+		}
+		delete(pod.Annotations, podAllocationIDAnnotation)
+		delete(pod.Annotations, podPublicIPAnnotation)
+		return true
+	}
+
+	publicIP := eip.Status.PublicIPAddress
+	if publicIP == "" {
+		publicIP = eip.Status.CarrierIPAddress
+	}
+	if pod.Annotations[podAllocationIDAnnotation] == eip.Status.AllocationId && pod.Annotations[podPublicIPAnnotation] == publicIP {
+		return false
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[podAllocationIDAnnotation] = eip.Status.AllocationId
+	pod.Annotations[podPublicIPAnnotation] = publicIP
+	return true
+}
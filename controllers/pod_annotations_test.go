@@ -0,0 +1,65 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+func TestUpdatePodAnnotations(t *testing.T) {
+	eip := &awsv1alpha1.EIP{Status: awsv1alpha1.EIPStatus{AllocationId: "eipalloc-1", PublicIPAddress: "1.2.3.4"}}
+	annotated := map[string]string{podAllocationIDAnnotation: "eipalloc-1", podPublicIPAnnotation: "1.2.3.4"}
+	other := map[string]string{podAllocationIDAnnotation: "eipalloc-2", podPublicIPAnnotation: "5.6.7.8"}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		add         bool
+		wantChanged bool
+		want        map[string]string
+	}{
+		{"add", nil, true, true, annotated},
+		{"add existing", annotated, true, false, annotated},
+		{"replace other EIP", other, true, true, annotated},
+		{"remove", annotated, false, true, map[string]string{}},
+		{"remove missing", nil, false, false, nil},
+		{"keep other EIP", other, false, false, other},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &corev1.Pod{}
+			if test.annotations != nil {
+				pod.Annotations = make(map[string]string)
+				for k, v := range test.annotations {
+					pod.Annotations[k] = v
+				}
+			}
+
+			if changed := updatePodAnnotations(pod, eip, test.add); changed != test.wantChanged {
+				t.Errorf("updatePodAnnotations() = %v, want %v", changed, test.wantChanged)
+			}
+			if !reflect.DeepEqual(pod.Annotations, test.want) {
+				t.Errorf("annotations = %v, want %v", pod.Annotations, test.want)
+			}
+		})
+	}
+}
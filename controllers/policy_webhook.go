@@ -0,0 +1,175 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// PolicyWebhookPath is the path the PolicyValidator is served at.
+const PolicyWebhookPath = "/validate-policy"
+
+// +kubebuilder:webhook:path=/validate-policy,mutating=false,failurePolicy=fail,sideEffects=None,groups=aws.k8s.logmein.com,resources=eips;enis;enipools,verbs=create;update,versions=v1alpha1,name=policy.aws.k8s.logmein.com,admissionReviewVersions=v1
+
+// PolicyValidator rejects EIPs, ENIs and ENIPools violating the EIPPolicies
+// and ClusterEIPPolicies of their namespace.
+type PolicyValidator struct {
+	Client  client.Reader
+	decoder *admission.Decoder
+}
+
+func (v *PolicyValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *PolicyValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	policies, err := getPolicies(ctx, v.Client, req.Namespace)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(policies) == 0 {
+		return admission.Allowed("")
+	}
+
+	switch req.Kind.Kind {
+	case "EIP":
+		err = v.validateEIP(ctx, req, policies)
+	case "ENI":
+		err = v.validateENI(ctx, req, policies)
+	case "ENIPool":
+		err = v.validateENIPool(ctx, req, policies)
+	}
+	if err != nil {
+		if _, ok := err.(policyViolation); ok {
+			return admission.Denied(err.Error())
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.Allowed("")
+}
+
+// policyViolation marks errors that lead to a denial rather than a failure.
+type policyViolation struct {
+	error
+}
+
+func violation(err error) error {
+	if err == nil {
+		return nil
+	}
+	return policyViolation{err}
+}
+
+func (v *PolicyValidator) validateEIP(ctx context.Context, req admission.Request, policies []awsv1alpha1.EIPPolicySpec) error {
+	var eip awsv1alpha1.EIP
+	if err := v.decoder.Decode(req, &eip); err != nil {
+		return err
+	}
+	if !eip.ObjectMeta.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	if req.Operation == admissionv1.Update {
+		var old awsv1alpha1.EIP
+		if err := v.decoder.DecodeRaw(req.OldObject, &old); err != nil {
+			return err
+		}
+		return violation(checkEIPPolicies(policies, &eip.Spec, &old.Spec))
+	}
+
+	if err := checkEIPPolicies(policies, &eip.Spec, nil); err != nil {
+		return violation(err)
+	}
+	count, err := countEIPs(ctx, v.Client, req.Namespace, eip.UID, false)
+	if err != nil {
+		return err
+	}
+	return violation(checkEIPQuota(policies, count+1))
+}
+
+func (v *PolicyValidator) validateENI(ctx context.Context, req admission.Request, policies []awsv1alpha1.EIPPolicySpec) error {
+	var eni awsv1alpha1.ENI
+	if err := v.decoder.Decode(req, &eni); err != nil {
+		return err
+	}
+	if !eni.ObjectMeta.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	if req.Operation == admissionv1.Update {
+		var old awsv1alpha1.ENI
+		if err := v.decoder.DecodeRaw(req.OldObject, &old); err != nil {
+			return err
+		}
+		return violation(checkENIPolicies(policies, getENIPolicySubject(&eni), getENIPolicySubject(&old)))
+	}
+
+	if err := checkENIPolicies(policies, getENIPolicySubject(&eni), nil); err != nil {
+		return violation(err)
+	}
+	count, err := countENIs(ctx, v.Client, req.Namespace, eni.UID, false)
+	if err != nil {
+		return err
+	}
+	return violation(checkENIQuota(policies, count+1))
+}
+
+func (v *PolicyValidator) validateENIPool(ctx context.Context, req admission.Request, policies []awsv1alpha1.EIPPolicySpec) error {
+	var pool awsv1alpha1.ENIPool
+	if err := v.decoder.Decode(req, &pool); err != nil {
+		return err
+	}
+	if !pool.ObjectMeta.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	var old *awsv1alpha1.ENIPool
+	if req.Operation == admissionv1.Update {
+		old = &awsv1alpha1.ENIPool{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return err
+		}
+	}
+
+	var oldSubject *eniPolicySubject
+	if old != nil {
+		oldSubject = getENIPoolPolicySubject(old)
+	}
+	if err := checkENIPolicies(policies, getENIPoolPolicySubject(&pool), oldSubject); err != nil {
+		return violation(err)
+	}
+
+	if old != nil && pool.Spec.Size <= old.Spec.Size {
+		return nil
+	}
+	count, err := countENIs(ctx, v.Client, req.Namespace, pool.UID, false)
+	if err != nil {
+		return err
+	}
+	return violation(checkENIQuota(policies, count+pool.Spec.Size))
+}
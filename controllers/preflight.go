@@ -0,0 +1,663 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Names of the controllers in permission checks. ClusterEIPs need the same
+// permissions as EIPs.
+const (
+	PreflightControllerEIP     = "EIP"
+	PreflightControllerENI     = "ENI"
+	PreflightControllerENIPool = "ENIPool"
+)
+
+var permissionMissing = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "k8s_aws_operator_iam_permission_missing",
+	Help: "Whether the preflight check found an EC2 action not to be permitted (1) or not (0).",
+}, []string{"action"})
+
+func init() {
+	metrics.Registry.MustRegister(permissionMissing)
+}
+
+// permissionCheck checks a permission by calling the EC2 action with DryRun
+// set, or without side effects otherwise. The IDs are placeholders; only the
+// authorization result of the call matters.
+type permissionCheck struct {
+	action      string
+	controllers []string
+	// feature needing the permission if not all uses of the controllers
+	// need it; missing permissions of features only cause warnings
+	feature string
+	// call is nil for actions without DryRun parameter
+	call func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error
+	// resourceTypes of the owned resources the action is called on. The
+	// placeholder IDs have no tags to evaluate aws:ResourceTag conditions
+	// against, so these actions are simulated with the ownership tags as
+	// context instead, if possible.
+	resourceTypes []string
+	// context of the simulation in addition to the cluster tag of the
+	// resource
+	context func(tags []*ec2.Tag) []*iam.ContextEntry
+}
+
+var permissionChecks = []permissionCheck{
+	{
+		action:      "AllocateAddress",
+		controllers: []string{PreflightControllerEIP},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.AllocateAddressWithContext(ctx, &ec2.AllocateAddressInput{
+				DryRun:            aws.Bool(true),
+				Domain:            aws.String("vpc"),
+				TagSpecifications: tagSpecifications(ec2.ResourceTypeElasticIp, tags),
+			})
+			return err
+		},
+	},
+	{
+		action:      "ReleaseAddress",
+		controllers: []string{PreflightControllerEIP},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.ReleaseAddressWithContext(ctx, &ec2.ReleaseAddressInput{
+				DryRun:       aws.Bool(true),
+				AllocationId: aws.String("eipalloc-00000000000000000"),
+			})
+			return err
+		},
+		resourceTypes: []string{"elastic-ip"},
+	},
+	{
+		action:      "DescribeAddresses",
+		controllers: []string{PreflightControllerEIP},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DescribeAddressesWithContext(ctx, &ec2.DescribeAddressesInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		action:      "AssociateAddress",
+		controllers: []string{PreflightControllerEIP},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.AssociateAddressWithContext(ctx, &ec2.AssociateAddressInput{
+				DryRun:             aws.Bool(true),
+				AllocationId:       aws.String("eipalloc-00000000000000000"),
+				NetworkInterfaceId: aws.String("eni-00000000000000000"),
+			})
+			return err
+		},
+		resourceTypes: []string{"elastic-ip"},
+	},
+	{
+		action:      "DisassociateAddress",
+		controllers: []string{PreflightControllerEIP},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DisassociateAddressWithContext(ctx, &ec2.DisassociateAddressInput{
+				DryRun:        aws.Bool(true),
+				AssociationId: aws.String("eipassoc-00000000000000000"),
+			})
+			return err
+		},
+		resourceTypes: []string{"elastic-ip"},
+	},
+	{
+		action:      "DescribeAddressesAttribute",
+		controllers: []string{PreflightControllerEIP},
+		feature:     "reverse DNS",
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DescribeAddressesAttributeWithContext(ctx, &ec2.DescribeAddressesAttributeInput{
+				DryRun:    aws.Bool(true),
+				Attribute: aws.String(ec2.AddressAttributeNameDomainName),
+			})
+			return err
+		},
+	},
+	{
+		action:      "ModifyAddressAttribute",
+		controllers: []string{PreflightControllerEIP},
+		feature:     "reverse DNS",
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.ModifyAddressAttributeWithContext(ctx, &ec2.ModifyAddressAttributeInput{
+				DryRun:       aws.Bool(true),
+				AllocationId: aws.String("eipalloc-00000000000000000"),
+				DomainName:   aws.String("example.com"),
+			})
+			return err
+		},
+		resourceTypes: []string{"elastic-ip"},
+	},
+	{
+		action:      "ResetAddressAttribute",
+		controllers: []string{PreflightControllerEIP},
+		feature:     "reverse DNS",
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.ResetAddressAttributeWithContext(ctx, &ec2.ResetAddressAttributeInput{
+				DryRun:       aws.Bool(true),
+				AllocationId: aws.String("eipalloc-00000000000000000"),
+				Attribute:    aws.String(ec2.AddressAttributeNameDomainName),
+			})
+			return err
+		},
+		resourceTypes: []string{"elastic-ip"},
+	},
+	{
+		action:      "DescribeAvailabilityZones",
+		controllers: []string{PreflightControllerEIP},
+		feature:     "network border groups",
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DescribeAvailabilityZonesWithContext(ctx, &ec2.DescribeAvailabilityZonesInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		action:      "DescribePublicIpv4Pools",
+		controllers: []string{PreflightControllerEIP},
+		feature:     "public IPv4 pools",
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			// no DryRun parameter, but read-only
+			_, err := ec2Client.DescribePublicIpv4PoolsWithContext(ctx, &ec2.DescribePublicIpv4PoolsInput{
+				MaxResults: aws.Int64(1),
+			})
+			return err
+		},
+	},
+	{
+		action:      "GetIpamPoolAllocations",
+		controllers: []string{PreflightControllerEIP},
+		feature:     "IPAM pools",
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.GetIpamPoolAllocationsWithContext(ctx, &ec2.GetIpamPoolAllocationsInput{
+				DryRun:     aws.Bool(true),
+				IpamPoolId: aws.String("ipam-pool-00000000000000000"),
+			})
+			return err
+		},
+	},
+	{
+		action:      "DescribeNetworkInterfaces",
+		controllers: []string{PreflightControllerEIP, PreflightControllerENI, PreflightControllerENIPool},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DescribeNetworkInterfacesWithContext(ctx, &ec2.DescribeNetworkInterfacesInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		action:      "CreateTags",
+		controllers: []string{PreflightControllerEIP, PreflightControllerENI, PreflightControllerENIPool},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.CreateTagsWithContext(ctx, &ec2.CreateTagsInput{
+				DryRun:    aws.Bool(true),
+				Resources: []*string{aws.String("eipalloc-00000000000000000")},
+				Tags:      tags,
+			})
+			return err
+		},
+		resourceTypes: []string{"elastic-ip", "network-interface"},
+		context:       requestTagsContext,
+	},
+	{
+		action:      "DeleteTags",
+		controllers: []string{PreflightControllerEIP, PreflightControllerENI, PreflightControllerENIPool},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DeleteTagsWithContext(ctx, &ec2.DeleteTagsInput{
+				DryRun:    aws.Bool(true),
+				Resources: []*string{aws.String("eipalloc-00000000000000000")},
+				Tags:      []*ec2.Tag{{Key: aws.String(eniPoolTagKey)}},
+			})
+			return err
+		},
+		resourceTypes: []string{"elastic-ip", "network-interface"},
+		context: func(tags []*ec2.Tag) []*iam.ContextEntry {
+			// e.g. the pool tag when claiming ENIs
+			return []*iam.ContextEntry{stringListContextEntry("aws:TagKeys", eniPoolTagKey)}
+		},
+	},
+	{
+		action:      "CreateNetworkInterface",
+		controllers: []string{PreflightControllerENI, PreflightControllerENIPool},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.CreateNetworkInterfaceWithContext(ctx, &ec2.CreateNetworkInterfaceInput{
+				DryRun:            aws.Bool(true),
+				SubnetId:          aws.String("subnet-00000000000000000"),
+				TagSpecifications: tagSpecifications(ec2.ResourceTypeNetworkInterface, tags),
+			})
+			return err
+		},
+	},
+	{
+		action:      "DeleteNetworkInterface",
+		controllers: []string{PreflightControllerENI, PreflightControllerENIPool},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DeleteNetworkInterfaceWithContext(ctx, &ec2.DeleteNetworkInterfaceInput{
+				DryRun:             aws.Bool(true),
+				NetworkInterfaceId: aws.String("eni-00000000000000000"),
+			})
+			return err
+		},
+		resourceTypes: []string{"network-interface"},
+	},
+	{
+		action:      "ModifyNetworkInterfaceAttribute",
+		controllers: []string{PreflightControllerENI},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.ModifyNetworkInterfaceAttributeWithContext(ctx, &ec2.ModifyNetworkInterfaceAttributeInput{
+				DryRun:             aws.Bool(true),
+				NetworkInterfaceId: aws.String("eni-00000000000000000"),
+				Description:        &ec2.AttributeValue{Value: aws.String("")},
+			})
+			return err
+		},
+		resourceTypes: []string{"network-interface"},
+	},
+	{
+		action:      "AttachNetworkInterface",
+		controllers: []string{PreflightControllerENI},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.AttachNetworkInterfaceWithContext(ctx, &ec2.AttachNetworkInterfaceInput{
+				DryRun:             aws.Bool(true),
+				NetworkInterfaceId: aws.String("eni-00000000000000000"),
+				InstanceId:         aws.String("i-00000000000000000"),
+				DeviceIndex:        aws.Int64(1),
+			})
+			return err
+		},
+		resourceTypes: []string{"network-interface"},
+	},
+	{
+		action:      "DetachNetworkInterface",
+		controllers: []string{PreflightControllerENI},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DetachNetworkInterfaceWithContext(ctx, &ec2.DetachNetworkInterfaceInput{
+				DryRun:       aws.Bool(true),
+				AttachmentId: aws.String("eni-attach-00000000000000000"),
+			})
+			return err
+		},
+		resourceTypes: []string{"network-interface"},
+	},
+	{
+		action:      "DescribeInstances",
+		controllers: []string{PreflightControllerENI},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		action:      "DescribeInstanceTypes",
+		controllers: []string{PreflightControllerENI},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DescribeInstanceTypesWithContext(ctx, &ec2.DescribeInstanceTypesInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		action:      "DescribeSubnets",
+		controllers: []string{PreflightControllerENI, PreflightControllerENIPool},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DescribeSubnetsWithContext(ctx, &ec2.DescribeSubnetsInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		action:      "DescribeSecurityGroups",
+		controllers: []string{PreflightControllerENI, PreflightControllerENIPool},
+		call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+			_, err := ec2Client.DescribeSecurityGroupsWithContext(ctx, &ec2.DescribeSecurityGroupsInput{
+				DryRun: aws.Bool(true),
+			})
+			return err
+		},
+	},
+	{
+		// no DryRun parameter, only simulated
+		action:        "AssignPrivateIpAddresses",
+		controllers:   []string{PreflightControllerENI},
+		resourceTypes: []string{"network-interface"},
+	},
+	{
+		// no DryRun parameter, only simulated
+		action:        "UnassignPrivateIpAddresses",
+		controllers:   []string{PreflightControllerENI},
+		resourceTypes: []string{"network-interface"},
+	},
+}
+
+// requestTagsContext returns the context of tagging a resource with the
+// tags.
+func requestTagsContext(tags []*ec2.Tag) []*iam.ContextEntry {
+	var entries []*iam.ContextEntry
+	var keys []string
+	for _, tag := range tags {
+		entries = append(entries, stringContextEntry("aws:RequestTag/"+aws.StringValue(tag.Key), aws.StringValue(tag.Value)))
+		keys = append(keys, aws.StringValue(tag.Key))
+	}
+	return append(entries, stringListContextEntry("aws:TagKeys", keys...))
+}
+
+func stringContextEntry(key, value string) *iam.ContextEntry {
+	return &iam.ContextEntry{
+		ContextKeyName:   aws.String(key),
+		ContextKeyType:   aws.String(iam.ContextKeyTypeEnumString),
+		ContextKeyValues: []*string{aws.String(value)},
+	}
+}
+
+func stringListContextEntry(key string, values ...string) *iam.ContextEntry {
+	return &iam.ContextEntry{
+		ContextKeyName:   aws.String(key),
+		ContextKeyType:   aws.String(iam.ContextKeyTypeEnumStringList),
+		ContextKeyValues: aws.StringSlice(values),
+	}
+}
+
+func tagSpecifications(resourceType string, tags []*ec2.Tag) []*ec2.TagSpecification {
+	return []*ec2.TagSpecification{{
+		ResourceType: aws.String(resourceType),
+		Tags:         tags,
+	}}
+}
+
+// isPermissionDenied returns whether the error of a permission check means
+// that the action is not permitted. Other errors, e.g. because of the
+// placeholder IDs, are returned after authorization and leave the action
+// permitted.
+func isPermissionDenied(err error) bool {
+	return isAWSErrorCode(err, "UnauthorizedOperation", "AccessDenied", "AccessDeniedException")
+}
+
+// preflightInterval is the interval the permission checks are re-run in, so
+// that changes of the IAM policy are noticed.
+const preflightInterval = 10 * time.Minute
+
+// Preflight checks whether the IAM role of the operator is permitted the EC2
+// actions the controllers need, with the default tags and the cluster
+// ownership tag of the operator so that tag-based conditions of the IAM policy
+// are evaluated. Only the operator's
+// own account and region are checked, not AWSAccounts.
+type Preflight struct {
+	EC2         ec2iface.EC2API
+	Tags        map[string]string
+	ClusterName string
+	Log         logr.Logger
+	// RequirePermissions makes the readiness check fail while permissions of
+	// running controllers are missing
+	RequirePermissions bool
+	// IAM and STS are used to simulate the actions on owned resources; the
+	// DryRun calls are used instead if they are nil or the simulation fails
+	IAM    iamiface.IAMAPI
+	STS    stsiface.STSAPI
+	Region string
+
+	mu sync.RWMutex
+	// actions not permitted, with the controllers needing them; actions of
+	// optional features are not included
+	missing map[string][]string
+	// controllers running, whose permissions the readiness check covers
+	controllers []string
+}
+
+// Run runs the permission checks, logs their results and updates the metric.
+func (p *Preflight) Run(ctx context.Context) {
+	p.run(ctx, permissionChecks)
+}
+
+// Start re-runs the permission checks periodically until the context is
+// done.
+func (p *Preflight) Start(ctx context.Context) error {
+	ticker := time.NewTicker(preflightInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			runCtx, cancel := context.WithTimeout(ctx, time.Minute)
+			p.Run(runCtx)
+			cancel()
+		}
+	}
+}
+
+// NeedLeaderElection returns false, as the readiness of every replica depends
+// on the permission checks.
+func (p *Preflight) NeedLeaderElection() bool {
+	return false
+}
+
+// AddController records that a controller is running, so that its missing
+// permissions fail the readiness check.
+func (p *Preflight) AddController(controller string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !containsString(p.controllers, controller) {
+		p.controllers = append(p.controllers, controller)
+	}
+}
+
+func (p *Preflight) run(ctx context.Context, checks []permissionCheck) {
+	tags := mergeTags(p.Tags, map[string]string{clusterTagKey: p.ClusterName})
+
+	var principal *simulationPrincipal
+	if p.IAM != nil && p.STS != nil {
+		var err error
+		principal, err = p.getSimulationPrincipal(ctx)
+		if err != nil {
+			p.Log.Info("unable to simulate permissions on owned resources, checking them with placeholder IDs", "error", err.Error())
+		}
+	}
+
+	missing := map[string][]string{}
+	for _, check := range checks {
+		log := p.Log.WithValues("action", "ec2:"+check.action)
+		var err error
+		simulated := false
+		if principal != nil && check.resourceTypes != nil {
+			err = p.simulate(ctx, principal, check, tags)
+			if err == nil || isPermissionDenied(err) {
+				simulated = true
+			} else {
+				log.Info("unable to simulate permission, checking with placeholder IDs", "error", err.Error())
+			}
+		}
+		if !simulated {
+			if check.call == nil {
+				continue
+			}
+			err = check.call(ctx, p.EC2, tags)
+		}
+		switch {
+		case err == nil || isAWSErrorCode(err, "DryRunOperation"):
+			permissionMissing.WithLabelValues(check.action).Set(0)
+		case isPermissionDenied(err):
+			permissionMissing.WithLabelValues(check.action).Set(1)
+			if check.feature != "" {
+				log.Info("permission missing; feature will not work", "feature", check.feature)
+				continue
+			}
+			log.Error(err, "permission missing", "controllers", check.controllers)
+			missing[check.action] = check.controllers
+		case errors.As(err, new(awserr.Error)):
+			// failed after authorization, e.g. because of the placeholder IDs
+			permissionMissing.WithLabelValues(check.action).Set(0)
+		default:
+			log.Error(err, "unable to check permission")
+		}
+	}
+
+	p.mu.Lock()
+	p.missing = missing
+	p.mu.Unlock()
+	if len(missing) == 0 {
+		p.Log.Info("all required permissions present")
+	}
+}
+
+// simulationPrincipal is the IAM role or user the permissions are simulated
+// for, and the account and partition of the owned resources.
+type simulationPrincipal struct {
+	arn       string
+	account   string
+	partition string
+}
+
+// getSimulationPrincipal returns the principal of the operator. Assumed roles
+// are resolved to the role, as permissions can only be simulated for roles
+// and users.
+func (p *Preflight) getSimulationPrincipal(ctx context.Context) (*simulationPrincipal, error) {
+	identity, err := p.STS.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+	callerARN, err := arn.Parse(aws.StringValue(identity.Arn))
+	if err != nil {
+		return nil, err
+	}
+	principal := &simulationPrincipal{
+		arn:       callerARN.String(),
+		account:   callerARN.AccountID,
+		partition: callerARN.Partition,
+	}
+	if callerARN.Service == "sts" && strings.HasPrefix(callerARN.Resource, "assumed-role/") {
+		// assumed-role/<role name>/<session name>
+		roleName := strings.Split(callerARN.Resource, "/")[1]
+		resp, err := p.IAM.GetRoleWithContext(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+		if err != nil {
+			return nil, err
+		}
+		principal.arn = aws.StringValue(resp.Role.Arn)
+	}
+	return principal, nil
+}
+
+// simulate simulates the action of the check on owned resources, with the
+// cluster tag of the operator as resource tag. It returns an AccessDenied
+// error if the action is not permitted.
+func (p *Preflight) simulate(ctx context.Context, principal *simulationPrincipal, check permissionCheck, tags []*ec2.Tag) error {
+	var resourceARNs []string
+	for _, resourceType := range check.resourceTypes {
+		resourceARNs = append(resourceARNs, arn.ARN{
+			Partition: principal.partition,
+			Service:   "ec2",
+			Region:    p.Region,
+			AccountID: principal.account,
+			Resource:  resourceType + "/*",
+		}.String())
+	}
+	contextEntries := []*iam.ContextEntry{stringContextEntry("aws:ResourceTag/"+clusterTagKey, getTag(tags, clusterTagKey))}
+	if check.context != nil {
+		contextEntries = append(contextEntries, check.context(tags)...)
+	}
+
+	resp, err := p.IAM.SimulatePrincipalPolicyWithContext(ctx, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principal.arn),
+		ActionNames:     aws.StringSlice([]string{"ec2:" + check.action}),
+		ResourceArns:    aws.StringSlice(resourceARNs),
+		ContextEntries:  contextEntries,
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.EvaluationResults) == 0 {
+		return errors.New("no evaluation results")
+	}
+	for _, result := range resp.EvaluationResults {
+		if aws.StringValue(result.EvalDecision) != iam.PolicyEvaluationDecisionTypeAllowed {
+			return awserr.New("AccessDenied", fmt.Sprintf("%s on owned resources: %s in simulation", check.action, aws.StringValue(result.EvalDecision)), nil)
+		}
+		for _, resourceResult := range result.ResourceSpecificResults {
+			if aws.StringValue(resourceResult.EvalResourceDecision) != iam.PolicyEvaluationDecisionTypeAllowed {
+				return awserr.New("AccessDenied", fmt.Sprintf("%s on %s: %s in simulation", check.action, aws.StringValue(resourceResult.EvalResourceName), aws.StringValue(resourceResult.EvalResourceDecision)), nil)
+			}
+		}
+	}
+	return nil
+}
+
+// MissingPermissions returns the EC2 actions the controller needs that are
+// not permitted.
+func (p *Preflight) MissingPermissions(controller string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var actions []string
+	for action, controllers := range p.missing {
+		if containsString(controllers, controller) {
+			actions = append(actions, "ec2:"+action)
+		}
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// Check is a readiness check failing while permissions of running controllers
+// are missing, if RequirePermissions is set. Otherwise missing permissions
+// only fail reconciling, while the pod keeps serving e.g. the admission
+// webhook.
+func (p *Preflight) Check(_ *http.Request) error {
+	if !p.RequirePermissions {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var actions []string
+	for action, controllers := range p.missing {
+		for _, controller := range controllers {
+			if containsString(p.controllers, controller) {
+				actions = append(actions, "ec2:"+action)
+				break
+			}
+		}
+	}
+	if len(actions) == 0 {
+		return nil
+	}
+	sort.Strings(actions)
+	return fmt.Errorf("missing permissions: %s", strings.Join(actions, ", "))
+}
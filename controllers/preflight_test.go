@@ -0,0 +1,172 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPreflight(t *testing.T) {
+	var checkedTags []*ec2.Tag
+	check := func(action string, err error, feature string, controllers ...string) permissionCheck {
+		return permissionCheck{
+			action:      action,
+			controllers: controllers,
+			feature:     feature,
+			call: func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+				checkedTags = tags
+				return err
+			},
+		}
+	}
+	denied := awserr.New("UnauthorizedOperation", "not authorized", nil)
+	checks := []permissionCheck{
+		check("AllocateAddress", awserr.New("DryRunOperation", "would have succeeded", nil), "", PreflightControllerEIP),
+		check("ReleaseAddress", denied, "", PreflightControllerEIP),
+		check("AssociateAddress", awserr.New("InvalidAllocationID.NotFound", "not found", nil), "", PreflightControllerEIP),
+		check("DescribeSubnets", denied, "", PreflightControllerENI, PreflightControllerENIPool),
+		check("DescribeInstances", errors.New("connection refused"), "", PreflightControllerENI),
+		check("GetIpamPoolAllocations", denied, "IPAM pools", PreflightControllerEIP),
+	}
+
+	p := &Preflight{Tags: map[string]string{"team": "network"}, ClusterName: "prod", Log: logr.Discard()}
+	p.run(context.Background(), checks)
+
+	if getTag(checkedTags, "team") != "network" || getTag(checkedTags, clusterTagKey) != "prod" || len(checkedTags) != 2 {
+		t.Errorf("checked with tags %v, want default and cluster tags", checkedTags)
+	}
+	for controller, want := range map[string][]string{
+		PreflightControllerEIP:     {"ec2:ReleaseAddress"},
+		PreflightControllerENI:     {"ec2:DescribeSubnets"},
+		PreflightControllerENIPool: {"ec2:DescribeSubnets"},
+	} {
+		if got := p.MissingPermissions(controller); !reflect.DeepEqual(got, want) {
+			t.Errorf("missing permissions of %s: got %v, want %v", controller, got, want)
+		}
+	}
+	if err := p.Check(nil); err != nil {
+		t.Errorf("got readiness %v without requiring permissions", err)
+	}
+	p.RequirePermissions = true
+	p.AddController(PreflightControllerEIP)
+	if err := p.Check(nil); err == nil || err.Error() != "missing permissions: ec2:ReleaseAddress" {
+		t.Errorf("got readiness %v", err)
+	}
+	p.AddController(PreflightControllerENIPool)
+	if err := p.Check(nil); err == nil || err.Error() != "missing permissions: ec2:DescribeSubnets, ec2:ReleaseAddress" {
+		t.Errorf("got readiness %v", err)
+	}
+	for action, want := range map[string]float64{"AllocateAddress": 0, "ReleaseAddress": 1, "AssociateAddress": 0, "GetIpamPoolAllocations": 1} {
+		if got := testutil.ToFloat64(permissionMissing.WithLabelValues(action)); got != want {
+			t.Errorf("metric of %s: got %v, want %v", action, got, want)
+		}
+	}
+
+	p.run(context.Background(), checks[:1])
+	if err := p.Check(nil); err != nil {
+		t.Errorf("got readiness %v after permissions were granted", err)
+	}
+}
+
+type fakeSTS struct {
+	stsiface.STSAPI
+}
+
+func (f *fakeSTS) GetCallerIdentityWithContext(ctx aws.Context, input *sts.GetCallerIdentityInput, opts ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{Arn: aws.String("arn:aws:sts::123456789012:assumed-role/operator/session")}, nil
+}
+
+// fakeIAM simulates a policy permitting ModifyNetworkInterfaceAttribute on
+// ENIs tagged with the cluster prod.
+type fakeIAM struct {
+	iamiface.IAMAPI
+	inputs []*iam.SimulatePrincipalPolicyInput
+}
+
+func (f *fakeIAM) GetRoleWithContext(ctx aws.Context, input *iam.GetRoleInput, opts ...request.Option) (*iam.GetRoleOutput, error) {
+	return &iam.GetRoleOutput{Role: &iam.Role{Arn: aws.String("arn:aws:iam::123456789012:role/path/" + aws.StringValue(input.RoleName))}}, nil
+}
+
+func (f *fakeIAM) SimulatePrincipalPolicyWithContext(ctx aws.Context, input *iam.SimulatePrincipalPolicyInput, opts ...request.Option) (*iam.SimulatePolicyResponse, error) {
+	f.inputs = append(f.inputs, input)
+	decision := iam.PolicyEvaluationDecisionTypeImplicitDeny
+	for _, entry := range input.ContextEntries {
+		if aws.StringValue(entry.ContextKeyName) == "aws:ResourceTag/"+clusterTagKey && aws.StringValue(entry.ContextKeyValues[0]) == "prod" &&
+			aws.StringValue(input.ActionNames[0]) == "ec2:ModifyNetworkInterfaceAttribute" {
+			decision = iam.PolicyEvaluationDecisionTypeAllowed
+		}
+	}
+	return &iam.SimulatePolicyResponse{EvaluationResults: []*iam.EvaluationResult{{
+		EvalActionName: input.ActionNames[0],
+		EvalDecision:   aws.String(decision),
+	}}}, nil
+}
+
+func TestPreflightSimulation(t *testing.T) {
+	denied := awserr.New("UnauthorizedOperation", "not authorized", nil)
+	call := func(ctx context.Context, ec2Client ec2iface.EC2API, tags []*ec2.Tag) error {
+		// placeholder IDs are not tagged
+		return denied
+	}
+	checks := []permissionCheck{
+		{action: "ModifyNetworkInterfaceAttribute", controllers: []string{PreflightControllerENI}, call: call, resourceTypes: []string{"network-interface"}},
+		{action: "AssignPrivateIpAddresses", controllers: []string{PreflightControllerENI}, resourceTypes: []string{"network-interface"}},
+		{action: "DescribeSubnets", controllers: []string{PreflightControllerENI}, call: call},
+	}
+
+	iamClient := &fakeIAM{}
+	p := &Preflight{ClusterName: "prod", Log: logr.Discard(), IAM: iamClient, STS: &fakeSTS{}, Region: "us-east-1"}
+	p.run(context.Background(), checks)
+
+	want := []string{"ec2:AssignPrivateIpAddresses", "ec2:DescribeSubnets"}
+	if got := p.MissingPermissions(PreflightControllerENI); !reflect.DeepEqual(got, want) {
+		t.Errorf("got missing permissions %v, want %v", got, want)
+	}
+	if len(iamClient.inputs) != 2 {
+		t.Fatalf("got %d simulations, want 2", len(iamClient.inputs))
+	}
+	input := iamClient.inputs[0]
+	if aws.StringValue(input.PolicySourceArn) != "arn:aws:iam::123456789012:role/path/operator" {
+		t.Errorf("simulated for %s", aws.StringValue(input.PolicySourceArn))
+	}
+	if got := aws.StringValueSlice(input.ResourceArns); !reflect.DeepEqual(got, []string{"arn:aws:ec2:us-east-1:123456789012:network-interface/*"}) {
+		t.Errorf("simulated on %v", got)
+	}
+
+	// without IAM, owned resources are checked with the placeholder IDs, and
+	// actions without DryRun are not checked
+	p = &Preflight{ClusterName: "prod", Log: logr.Discard()}
+	p.run(context.Background(), checks)
+	want = []string{"ec2:DescribeSubnets", "ec2:ModifyNetworkInterfaceAttribute"}
+	if got := p.MissingPermissions(PreflightControllerENI); !reflect.DeepEqual(got, want) {
+		t.Errorf("got missing permissions %v without IAM, want %v", got, want)
+	}
+}
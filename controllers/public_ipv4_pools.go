@@ -0,0 +1,148 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// getIPAMPublicIPv4Pools returns the public IPv4 pools in the region of the
+// EIP that the given IPAM pool has provisioned CIDRs to, in the order of the
+// IPAM pool's allocations.
+func (r *EIPReconciler) getIPAMPublicIPv4Pools(ctx context.Context, ipamPoolID string) ([]*ec2.PublicIpv4Pool, error) {
+	var poolIDs []string
+	err := r.EC2.GetIpamPoolAllocationsPagesWithContext(ctx, &ec2.GetIpamPoolAllocationsInput{
+		IpamPoolId: aws.String(ipamPoolID),
+	}, func(page *ec2.GetIpamPoolAllocationsOutput, lastPage bool) bool {
+		for _, allocation := range page.IpamPoolAllocations {
+			if aws.StringValue(allocation.ResourceType) == ec2.IpamPoolAllocationResourceTypeEc2PublicIpv4Pool &&
+				!containsString(poolIDs, aws.StringValue(allocation.ResourceId)) {
+				poolIDs = append(poolIDs, aws.StringValue(allocation.ResourceId))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the IPAM pool may have provisioned CIDRs to pools in other regions,
+	// which are not returned here
+	found := make(map[string]*ec2.PublicIpv4Pool)
+	err = r.EC2.DescribePublicIpv4PoolsPagesWithContext(ctx, &ec2.DescribePublicIpv4PoolsInput{},
+		func(page *ec2.DescribePublicIpv4PoolsOutput, lastPage bool) bool {
+			for _, pool := range page.PublicIpv4Pools {
+				found[aws.StringValue(pool.PoolId)] = pool
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var pools []*ec2.PublicIpv4Pool
+	for _, poolID := range poolIDs {
+		if pool, ok := found[poolID]; ok {
+			pools = append(pools, pool)
+		}
+	}
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("IPAM pool %s has no public IPv4 pools in %s", ipamPoolID, describeRegion(r.region))
+	}
+	return pools, nil
+}
+
+// choosePublicIPv4Pool chooses the pool to allocate an EIP from according to
+// the allocation strategy. The pools are considered in the given order, or in
+// the order they were described in if no order is given.
+func (r *EIPReconciler) choosePublicIPv4Pool(order []string, pools []*ec2.PublicIpv4Pool, strategy awsv1alpha1.PoolAllocationStrategy) (string, error) {
+	available := make(map[string]int64)
+	for _, pool := range pools {
+		available[aws.StringValue(pool.PoolId)] = aws.Int64Value(pool.TotalAvailableAddressCount)
+	}
+	if len(order) == 0 {
+		for _, pool := range pools {
+			order = append(order, aws.StringValue(pool.PoolId))
+		}
+	}
+
+	start := 0
+	key := strings.Join(order, ",")
+	switch strategy {
+	case awsv1alpha1.PoolAllocationStrategyRoundRobin:
+		if r.roundRobin != nil {
+			if next, ok := r.roundRobin.Load(key); ok {
+				start = next.(int)
+			}
+		}
+	case awsv1alpha1.PoolAllocationStrategyRandom:
+		start = rand.Int()
+	}
+
+	poolID, next, err := selectPublicIPv4Pool(order, available, strategy, start)
+	if err != nil {
+		return "", err
+	}
+	if strategy == awsv1alpha1.PoolAllocationStrategyRoundRobin && r.roundRobin != nil {
+		r.roundRobin.Store(key, next)
+	}
+	return poolID, nil
+}
+
+// selectPublicIPv4Pool selects one of the pools with available addresses.
+// start is the index to start at for the RoundRobin strategy and the random
+// offset for the Random strategy. It also returns the index to start at the
+// next time.
+func selectPublicIPv4Pool(poolIDs []string, available map[string]int64, strategy awsv1alpha1.PoolAllocationStrategy, start int) (string, int, error) {
+	var candidates []int
+	for i, poolID := range poolIDs {
+		if available[poolID] > 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", 0, fmt.Errorf("no addresses available in public IPv4 pools %v", poolIDs)
+	}
+
+	chosen := candidates[0]
+	switch strategy {
+	case awsv1alpha1.PoolAllocationStrategyOrderedFallback:
+	case awsv1alpha1.PoolAllocationStrategyRoundRobin:
+		for i := 0; i < len(poolIDs); i++ {
+			if index := (start + i) % len(poolIDs); available[poolIDs[index]] > 0 {
+				chosen = index
+				break
+			}
+		}
+	case awsv1alpha1.PoolAllocationStrategyRandom:
+		chosen = candidates[start%len(candidates)]
+	default:
+		for _, index := range candidates {
+			if available[poolIDs[index]] > available[poolIDs[chosen]] {
+				chosen = index
+			}
+		}
+	}
+	return poolIDs[chosen], (chosen + 1) % len(poolIDs), nil
+}
@@ -0,0 +1,58 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+func TestSelectPublicIPv4Pool(t *testing.T) {
+	poolIDs := []string{"pool-a", "pool-b", "pool-c"}
+	available := map[string]int64{"pool-a": 0, "pool-b": 2, "pool-c": 5}
+
+	tests := []struct {
+		name      string
+		available map[string]int64
+		strategy  awsv1alpha1.PoolAllocationStrategy
+		start     int
+		want      string
+		wantNext  int
+		wantErr   bool
+	}{
+		{"most free by default", available, "", 0, "pool-c", 0, false},
+		{"most free", available, awsv1alpha1.PoolAllocationStrategyMostFree, 0, "pool-c", 0, false},
+		{"ordered fallback skips exhausted", available, awsv1alpha1.PoolAllocationStrategyOrderedFallback, 0, "pool-b", 2, false},
+		{"round robin skips exhausted", available, awsv1alpha1.PoolAllocationStrategyRoundRobin, 0, "pool-b", 2, false},
+		{"round robin continues", available, awsv1alpha1.PoolAllocationStrategyRoundRobin, 2, "pool-c", 0, false},
+		{"round robin wraps around", available, awsv1alpha1.PoolAllocationStrategyRoundRobin, 3, "pool-b", 2, false},
+		{"random among available", available, awsv1alpha1.PoolAllocationStrategyRandom, 3, "pool-c", 0, false},
+		{"all exhausted", map[string]int64{}, awsv1alpha1.PoolAllocationStrategyMostFree, 0, "", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, next, err := selectPublicIPv4Pool(poolIDs, test.available, test.strategy, test.start)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("selectPublicIPv4Pool() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want || next != test.wantNext {
+				t.Errorf("selectPublicIPv4Pool() = %s, %d, want %s, %d", got, next, test.want, test.wantNext)
+			}
+		})
+	}
+}
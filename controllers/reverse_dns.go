@@ -0,0 +1,185 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+const (
+	// reverseDNSPendingPollInterval is how often the PTR record of an EIP is
+	// checked while an update is in progress.
+	reverseDNSPendingPollInterval = 30 * time.Second
+	// reverseDNSRetryInterval is how often setting a PTR record is retried
+	// after it was rejected, e.g. because the forward record did not resolve
+	// to the EIP yet.
+	reverseDNSRetryInterval = 5 * time.Minute
+)
+
+// reconcileReverseDNS sets or resets the PTR record of an allocated EIP and
+// updates its status. It returns whether the status changed and after which
+// time the EIP needs to be reconciled again. Errors are recorded in the
+// status instead of being returned, so that they do not block assigning the
+// EIP.
+func (r *EIPReconciler) reconcileReverseDNS(ctx context.Context, eip *awsv1alpha1.EIP, log logr.Logger) (bool, time.Duration) {
+	changed, requeueAfter, err := r.updateReverseDNS(ctx, eip, log)
+	if err == nil {
+		return changed, requeueAfter
+	}
+	if isDryRun(err) {
+		logPlannedAction(log, err)
+		return changed, dryRunRequeueInterval
+	}
+
+	log.Error(err, "unable to reconcile reverse DNS")
+	status := &awsv1alpha1.EIPReverseDNSStatus{}
+	if eip.Status.ReverseDNS != nil {
+		status = eip.Status.ReverseDNS.DeepCopy()
+	}
+	status.Error = err.Error()
+	if reflect.DeepEqual(eip.Status.ReverseDNS, status) {
+		return false, transientRequeueInterval
+	}
+	eip.Status.ReverseDNS = status
+	return true, transientRequeueInterval
+}
+
+func (r *EIPReconciler) updateReverseDNS(ctx context.Context, eip *awsv1alpha1.EIP, log logr.Logger) (bool, time.Duration, error) {
+	previous := eip.Status.ReverseDNS
+	if eip.Spec.ReverseDNS == "" && (previous == nil || previous.DomainName == "") {
+		// never managed by the operator
+		return false, 0, nil
+	}
+
+	resp, err := r.EC2.DescribeAddressesAttributeWithContext(ctx, &ec2.DescribeAddressesAttributeInput{
+		AllocationIds: []*string{aws.String(eip.Status.AllocationId)},
+		Attribute:     aws.String(ec2.AddressAttributeNameDomainName),
+	})
+	if err != nil {
+		return false, 0, err
+	}
+
+	status := &awsv1alpha1.EIPReverseDNSStatus{}
+	if previous != nil {
+		status.DomainName = previous.DomainName
+		status.Error = previous.Error
+		status.LastAttemptTime = previous.LastAttemptTime
+	}
+	var update *ec2.PtrUpdateStatus
+	if len(resp.Addresses) > 0 {
+		status.PTRRecord = aws.StringValue(resp.Addresses[0].PtrRecord)
+		update = resp.Addresses[0].PtrRecordUpdate
+	}
+
+	var requeueAfter time.Duration
+	if update != nil && strings.EqualFold(aws.StringValue(update.Status), "pending") {
+		// wait for the update in progress before changing anything
+		status.UpdateStatus = aws.StringValue(update.Status)
+		requeueAfter = reverseDNSPendingPollInterval
+	} else if eip.Spec.ReverseDNS == "" {
+		log.Info("resetting reverse DNS", "ptrRecord", status.PTRRecord)
+		if _, err := r.EC2.ResetAddressAttributeWithContext(ctx, &ec2.ResetAddressAttributeInput{
+			AllocationId: aws.String(eip.Status.AllocationId),
+			Attribute:    aws.String(ec2.AddressAttributeNameDomainName),
+		}); err != nil {
+			return false, 0, err
+		}
+		status.DomainName = ""
+		status.Error = ""
+		status.LastAttemptTime = nil
+		requeueAfter = reverseDNSPendingPollInterval
+	} else if !equalDomainNames(status.PTRRecord, eip.Spec.ReverseDNS) {
+		// the last update to the same domain name failed asynchronously
+		failed := update != nil && aws.StringValue(update.Reason) != "" && equalDomainNames(aws.StringValue(update.Value), eip.Spec.ReverseDNS)
+		if failed {
+			status.Error = aws.StringValue(update.Reason)
+		}
+		if backOff := reverseDNSBackOff(status, eip.Spec.ReverseDNS, time.Now()); backOff > 0 {
+			// retry the failed domain name later
+			requeueAfter = backOff
+		} else {
+			log.Info("setting reverse DNS", "domainName", eip.Spec.ReverseDNS, "ptrRecord", status.PTRRecord)
+			now := metav1.Now()
+			status.DomainName = eip.Spec.ReverseDNS
+			status.LastAttemptTime = &now
+			if _, err := r.EC2.ModifyAddressAttributeWithContext(ctx, &ec2.ModifyAddressAttributeInput{
+				AllocationId: aws.String(eip.Status.AllocationId),
+				DomainName:   aws.String(eip.Spec.ReverseDNS),
+			}); err != nil {
+				if !isRejectedRequest(err) {
+					return false, 0, err
+				}
+				// e.g. the forward record does not resolve to the EIP yet
+				log.Info("reverse DNS rejected", "domainName", eip.Spec.ReverseDNS, "error", err.Error())
+				status.Error = err.Error()
+				requeueAfter = reverseDNSRetryInterval
+			} else {
+				if !failed {
+					// the reason of a failed update is kept until the PTR
+					// record is set
+					status.Error = ""
+				}
+				requeueAfter = reverseDNSPendingPollInterval
+			}
+		}
+	} else {
+		status.DomainName = eip.Spec.ReverseDNS
+		status.Error = ""
+		status.LastAttemptTime = nil
+	}
+
+	if reflect.DeepEqual(previous, status) {
+		return false, requeueAfter, nil
+	}
+	eip.Status.ReverseDNS = status
+	return true, requeueAfter, nil
+}
+
+// reverseDNSBackOff returns how long to wait before requesting the domain
+// name again, if the last request for it failed.
+func reverseDNSBackOff(status *awsv1alpha1.EIPReverseDNSStatus, domainName string, now time.Time) time.Duration {
+	if status.Error == "" || status.LastAttemptTime == nil || !equalDomainNames(status.DomainName, domainName) {
+		return 0
+	}
+	if backOff := status.LastAttemptTime.Add(reverseDNSRetryInterval).Sub(now); backOff > 0 {
+		return backOff
+	}
+	return 0
+}
+
+// equalDomainNames compares domain names, ignoring the trailing dot of fully
+// qualified names and case.
+func equalDomainNames(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
+
+// isRejectedRequest returns whether EC2 rejected a request as invalid, as
+// opposed to failing to process it.
+func isRejectedRequest(err error) bool {
+	reqErr, ok := err.(awserr.RequestFailure)
+	return ok && reqErr.StatusCode() == 400 && reqErr.Code() != "RequestLimitExceeded"
+}
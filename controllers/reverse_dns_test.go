@@ -0,0 +1,102 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+func TestEqualDomainNames(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"mail.example.com.", "mail.example.com", true},
+		{"Mail.Example.com", "mail.example.com.", true},
+		{"mail.example.com", "smtp.example.com", false},
+		{"", "mail.example.com", false},
+	}
+
+	for _, test := range tests {
+		if got := equalDomainNames(test.a, test.b); got != test.want {
+			t.Errorf("equalDomainNames(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestReverseDNSBackOff(t *testing.T) {
+	now := time.Now()
+	attempted := metav1.NewTime(now.Add(-time.Minute))
+	longAgo := metav1.NewTime(now.Add(-time.Hour))
+
+	tests := []struct {
+		name   string
+		status awsv1alpha1.EIPReverseDNSStatus
+		want   time.Duration
+	}{
+		{"failed recently", awsv1alpha1.EIPReverseDNSStatus{DomainName: "mail.example.com", Error: "forward record mismatch", LastAttemptTime: &attempted}, reverseDNSRetryInterval - time.Minute},
+		{"failed long ago", awsv1alpha1.EIPReverseDNSStatus{DomainName: "mail.example.com", Error: "forward record mismatch", LastAttemptTime: &longAgo}, 0},
+		{"failed for other domain name", awsv1alpha1.EIPReverseDNSStatus{DomainName: "smtp.example.com", Error: "forward record mismatch", LastAttemptTime: &attempted}, 0},
+		{"not failed", awsv1alpha1.EIPReverseDNSStatus{DomainName: "mail.example.com", LastAttemptTime: &attempted}, 0},
+		{"never attempted", awsv1alpha1.EIPReverseDNSStatus{DomainName: "mail.example.com", Error: "forward record mismatch"}, 0},
+	}
+
+	for _, test := range tests {
+		if got := reverseDNSBackOff(&test.status, "mail.example.com.", now); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+type failingAddressAttributes struct {
+	ec2iface.EC2API
+}
+
+func (f *failingAddressAttributes) DescribeAddressesAttributeWithContext(ctx aws.Context, input *ec2.DescribeAddressesAttributeInput, opts ...request.Option) (*ec2.DescribeAddressesAttributeOutput, error) {
+	return nil, errors.New("connection reset")
+}
+
+func TestReconcileReverseDNSError(t *testing.T) {
+	r := &EIPReconciler{EC2: &failingAddressAttributes{}}
+	eip := &awsv1alpha1.EIP{
+		Spec:   awsv1alpha1.EIPSpec{ReverseDNS: "mail.example.com"},
+		Status: awsv1alpha1.EIPStatus{AllocationId: "eipalloc-1"},
+	}
+
+	changed, requeueAfter := r.reconcileReverseDNS(context.Background(), eip, logr.Discard())
+	if !changed || eip.Status.ReverseDNS == nil || eip.Status.ReverseDNS.Error != "connection reset" {
+		t.Errorf("got changed %t, status %+v", changed, eip.Status.ReverseDNS)
+	}
+	if requeueAfter != transientRequeueInterval {
+		t.Errorf("got requeue after %v", requeueAfter)
+	}
+
+	if changed, _ := r.reconcileReverseDNS(context.Background(), eip, logr.Discard()); changed {
+		t.Error("got changed for the same error")
+	}
+}
@@ -0,0 +1,116 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/go-logr/logr"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// defaultDNSRecordTTL is the TTL of records without TTL.
+const defaultDNSRecordTTL = 300
+
+// reconcileDNS creates the Route 53 record of an assigned EIP, and deletes
+// records that were removed from or changed in the spec. It returns whether
+// the status changed.
+func (r *EIPReconciler) reconcileDNS(ctx context.Context, eip *awsv1alpha1.EIP, log logr.Logger) (bool, error) {
+	desired := eip.Spec.DNS
+	if desired != nil {
+		desired = desired.DeepCopy()
+		if desired.TTL == 0 {
+			desired.TTL = defaultDNSRecordTTL
+		}
+	}
+
+	changed := false
+	if current := eip.Status.DNS; current != nil && (desired == nil || !sameDNSRecordName(current, desired)) {
+		log.Info("deleting DNS record", "hostedZoneId", current.HostedZoneID, "name", current.Name)
+		if err := r.deleteDNSRecord(ctx, eip); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	if desired != nil && (eip.Status.DNS == nil || eip.Status.DNS.TTL != desired.TTL) && eip.Status.State == awsv1alpha1.EIPStateAssigned {
+		log.Info("creating DNS record", "hostedZoneId", desired.HostedZoneID, "name", desired.Name)
+		if err := r.changeDNSRecord(ctx, route53.ChangeActionUpsert, desired, eip); err != nil {
+			return false, err
+		}
+		eip.Status.DNS = desired
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// deleteDNSRecord deletes the Route 53 record of an EIP, if any.
+func (r *EIPReconciler) deleteDNSRecord(ctx context.Context, eip *awsv1alpha1.EIP) error {
+	if eip.Status.DNS == nil {
+		return nil
+	}
+	err := r.changeDNSRecord(ctx, route53.ChangeActionDelete, eip.Status.DNS, eip)
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == route53.ErrCodeInvalidChangeBatch &&
+		strings.Contains(awsErr.Message(), "not found") {
+		// already deleted
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	eip.Status.DNS = nil
+	return nil
+}
+
+func (r *EIPReconciler) changeDNSRecord(ctx context.Context, action string, record *awsv1alpha1.EIPDNSRecord, eip *awsv1alpha1.EIP) error {
+	if r.Route53 == nil {
+		return fmt.Errorf("Route 53 is not configured")
+	}
+	ip := eip.Status.PublicIPAddress
+	if ip == "" {
+		ip = eip.Status.CarrierIPAddress
+	}
+
+	_, err := r.Route53.ChangeResourceRecordSetsWithContext(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(record.HostedZoneID),
+		ChangeBatch: &route53.ChangeBatch{
+			Comment: aws.String(fmt.Sprintf("EIP %s", eip.Status.AllocationId)),
+			Changes: []*route53.Change{{
+				Action: aws.String(action),
+				ResourceRecordSet: &route53.ResourceRecordSet{
+					Name:            aws.String(record.Name),
+					Type:            aws.String(route53.RRTypeA),
+					TTL:             aws.Int64(record.TTL),
+					ResourceRecords: []*route53.ResourceRecord{{Value: aws.String(ip)}},
+				},
+			}},
+		},
+	})
+	return err
+}
+
+// sameDNSRecordName returns whether two records are the same record, which
+// might differ in TTL.
+func sameDNSRecordName(a, b *awsv1alpha1.EIPDNSRecord) bool {
+	return a.HostedZoneID == b.HostedZoneID && equalDomainNames(a.Name, b.Name)
+}
@@ -0,0 +1,96 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
+)
+
+// fakeRoute53 records the changes made to resource record sets.
+type fakeRoute53 struct {
+	route53iface.Route53API
+	changes []string
+}
+
+func (f *fakeRoute53) ChangeResourceRecordSetsWithContext(ctx aws.Context, input *route53.ChangeResourceRecordSetsInput, opts ...request.Option) (*route53.ChangeResourceRecordSetsOutput, error) {
+	for _, change := range input.ChangeBatch.Changes {
+		set := change.ResourceRecordSet
+		f.changes = append(f.changes, aws.StringValue(change.Action)+" "+aws.StringValue(input.HostedZoneId)+" "+
+			aws.StringValue(set.Name)+" "+aws.StringValue(set.ResourceRecords[0].Value))
+	}
+	return &route53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+func TestReconcileDNS(t *testing.T) {
+	record := &awsv1alpha1.EIPDNSRecord{HostedZoneID: "Z1", Name: "www.example.com", TTL: 300}
+	renamed := &awsv1alpha1.EIPDNSRecord{HostedZoneID: "Z1", Name: "api.example.com", TTL: 300}
+
+	tests := []struct {
+		name        string
+		state       awsv1alpha1.EIPState
+		spec        *awsv1alpha1.EIPDNSRecord
+		status      *awsv1alpha1.EIPDNSRecord
+		wantChanges []string
+		wantStatus  *awsv1alpha1.EIPDNSRecord
+	}{
+		{"not assigned yet", "allocated", record, nil, nil, nil},
+		{"assigned", "assigned", &awsv1alpha1.EIPDNSRecord{HostedZoneID: "Z1", Name: "www.example.com"}, nil,
+			[]string{"UPSERT Z1 www.example.com 1.2.3.4"}, record},
+		{"unchanged", "assigned", record, record, nil, record},
+		{"removed", "allocated", nil, record, []string{"DELETE Z1 www.example.com 1.2.3.4"}, nil},
+		{"renamed", "assigned", renamed, record,
+			[]string{"DELETE Z1 www.example.com 1.2.3.4", "UPSERT Z1 api.example.com 1.2.3.4"}, renamed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeRoute53{}
+			r := &EIPReconciler{Route53: fake}
+			eip := &awsv1alpha1.EIP{
+				Spec: awsv1alpha1.EIPSpec{DNS: test.spec},
+				Status: awsv1alpha1.EIPStatus{
+					State:           test.state,
+					PublicIPAddress: "1.2.3.4",
+					DNS:             test.status,
+				},
+			}
+
+			changed, err := r.reconcileDNS(context.Background(), eip, ctrl.Log)
+			if err != nil {
+				t.Fatalf("reconcileDNS() error = %v", err)
+			}
+			if !reflect.DeepEqual(fake.changes, test.wantChanges) {
+				t.Errorf("changes = %v, want %v", fake.changes, test.wantChanges)
+			}
+			if changed != (len(test.wantChanges) > 0) {
+				t.Errorf("reconcileDNS() = %v, want %v", changed, len(test.wantChanges) > 0)
+			}
+			if !reflect.DeepEqual(eip.Status.DNS, test.wantStatus) {
+				t.Errorf("status = %v, want %v", eip.Status.DNS, test.wantStatus)
+			}
+		})
+	}
+}
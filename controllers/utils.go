@@ -28,6 +28,18 @@ func removeString(slice []string, s string) (result []string) {
 	return
 }
 
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func isTagPresent(tags []*ec2.Tag, searchedTag *ec2.Tag) bool {
 	for _, tag := range tags {
 		if tag.Key != nil && *tag.Key == *searchedTag.Key {
@@ -16,13 +16,20 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+	"github.com/aws/aws-sdk-go/service/sts"
 	awsv1alpha1 "github.com/logmein/k8s-aws-operator/api/v1alpha1"
 	"github.com/logmein/k8s-aws-operator/controllers"
 	corev1 "k8s.io/api/core/v1"
@@ -31,6 +38,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -46,12 +54,22 @@ func init() {
 }
 
 func main() {
-	var metricsAddr, region, leaderElectionID, leaderElectionNamespace, defaultTags string
+	var metricsAddr, probeAddr, region, clusterName, leaderElectionID, leaderElectionNamespace, defaultTags, webhookCertDir string
+	var webhookPort int
+	var dryRun, preflightEnabled, requirePermissions, adoptUntagged bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&probeAddr, "health-probe-addr", ":8081", "The address the readiness probe endpoint binds to.")
 	flag.StringVar(&region, "region", "", "AWS region")
 	flag.StringVar(&leaderElectionID, "leader-election-id", "k8s-aws-operator", "the name of the configmap do use as leader election lock")
 	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "", "the namespace in which the leader election lock will be held")
+	flag.StringVar(&clusterName, "cluster-name", "default", "the name of the cluster, tagged on created resources to mark them as owned by it; needs to be unique per AWS account")
+	flag.BoolVar(&adoptUntagged, "adopt-untagged", false, "adopt all existing EIPs and ENIs that are not tagged as owned by any cluster, not only those recorded in the status of their object")
 	flag.StringVar(&defaultTags, "default-tags", "", "default tags to add to created resources, in the format key1=value1,key2=value2")
+	flag.IntVar(&webhookPort, "webhook-port", 0, "the port the admission webhook enforcing EIPPolicies binds to; disabled if 0")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "", "the directory containing tls.crt and tls.key for the admission webhook")
+	flag.BoolVar(&dryRun, "dry-run", false, "log and record mutating EC2 and Route 53 calls, and the Kubernetes writes depending on them, as planned actions instead of executing them")
+	flag.BoolVar(&preflightEnabled, "preflight", true, "check at startup and periodically whether the EC2 permissions needed by the controllers are granted")
+	flag.BoolVar(&requirePermissions, "require-permissions", false, "do not start controllers whose EC2 permissions are missing according to the preflight check, and fail readiness while permissions of running controllers are missing")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -71,14 +89,24 @@ func main() {
 		os.Exit(1)
 	}
 
-	ec2 := ec2.New(sess)
+	var ec2Client ec2iface.EC2API = ec2.New(sess)
+	var route53Client route53iface.Route53API = route53.New(sess)
+	if dryRun {
+		setupLog.Info("dry run: mutating EC2 and Route 53 calls are only planned")
+		ec2Client = controllers.NewDryRunEC2(ec2Client)
+		route53Client = controllers.NewDryRunRoute53(route53Client)
+	}
+	ec2Clients := &controllers.EC2Clients{Session: sess, DryRun: dryRun}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                  scheme,
 		MetricsBindAddress:      metricsAddr,
+		HealthProbeBindAddress:  probeAddr,
 		LeaderElection:          leaderElectionNamespace != "" && leaderElectionID != "",
 		LeaderElectionNamespace: leaderElectionNamespace,
 		LeaderElectionID:        leaderElectionID,
+		Port:                    webhookPort,
+		CertDir:                 webhookCertDir,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -98,38 +126,143 @@ func main() {
 		setupLog.Info("Default tags set", "tags", defaultTagsMap)
 	}
 
-	err = (&controllers.EIPReconciler{
-		Client:           cachingClient,
-		NonCachingClient: nonCachingClient,
-		Log:              ctrl.Log.WithName("controllers").WithName("EIP"),
-		EC2:              ec2,
-		Tags:             defaultTagsMap,
+	var preflight *controllers.Preflight
+	if preflightEnabled {
+		// the actual client, as the checks are made with DryRun anyway
+		preflight = &controllers.Preflight{
+			EC2:                ec2.New(sess),
+			Tags:               defaultTagsMap,
+			ClusterName:        clusterName,
+			Log:                ctrl.Log.WithName("preflight"),
+			RequirePermissions: requirePermissions,
+			IAM:                iam.New(sess),
+			STS:                sts.New(sess),
+			Region:             aws.StringValue(sess.Config.Region),
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		preflight.Run(ctx)
+		cancel()
+		if err := mgr.Add(preflight); err != nil {
+			setupLog.Error(err, "unable to add preflight check")
+			os.Exit(1)
+		}
+		if err := mgr.AddReadyzCheck("permissions", preflight.Check); err != nil {
+			setupLog.Error(err, "unable to add readiness check")
+			os.Exit(1)
+		}
+	}
+
+	if !skipController(preflight, requirePermissions, "EIP", controllers.PreflightControllerEIP) {
+		err = (&controllers.EIPReconciler{
+			Client:           cachingClient,
+			NonCachingClient: nonCachingClient,
+			Log:              ctrl.Log.WithName("controllers").WithName("EIP"),
+			EC2:              ec2Client,
+			EC2Clients:       ec2Clients,
+			Route53:          route53Client,
+			Tags:             defaultTagsMap,
+			ClusterName:      clusterName,
+			AdoptUntagged:    adoptUntagged,
+			DryRun:           dryRun,
+		}).SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "EIP")
+			os.Exit(1)
+		}
+	}
+	if !skipController(preflight, requirePermissions, "ClusterEIP", controllers.PreflightControllerEIP) {
+		err = (&controllers.EIPReconciler{
+			Client:           cachingClient,
+			NonCachingClient: nonCachingClient,
+			Log:              ctrl.Log.WithName("controllers").WithName("ClusterEIP"),
+			EC2:              ec2Client,
+			EC2Clients:       ec2Clients,
+			Route53:          route53Client,
+			Tags:             defaultTagsMap,
+			ClusterName:      clusterName,
+			AdoptUntagged:    adoptUntagged,
+			DryRun:           dryRun,
+			ClusterScoped:    true,
+		}).SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ClusterEIP")
+			os.Exit(1)
+		}
+	}
+	if !skipController(preflight, requirePermissions, "ENI", controllers.PreflightControllerENI) {
+		err = (&controllers.ENIReconciler{
+			Client:           cachingClient,
+			NonCachingClient: nonCachingClient,
+			Log:              ctrl.Log.WithName("controllers").WithName("ENI"),
+			EC2:              ec2Client,
+			EC2Clients:       ec2Clients,
+			Tags:             defaultTagsMap,
+			ClusterName:      clusterName,
+			AdoptUntagged:    adoptUntagged,
+			DryRun:           dryRun,
+		}).SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ENI")
+			os.Exit(1)
+		}
+	}
+	if !skipController(preflight, requirePermissions, "ENIPool", controllers.PreflightControllerENIPool) {
+		err = (&controllers.ENIPoolReconciler{
+			Client:        cachingClient,
+			Log:           ctrl.Log.WithName("controllers").WithName("ENIPool"),
+			EC2:           ec2Client,
+			EC2Clients:    ec2Clients,
+			Tags:          defaultTagsMap,
+			ClusterName:   clusterName,
+			AdoptUntagged: adoptUntagged,
+		}).SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ENIPool")
+			os.Exit(1)
+		}
+	}
+
+	err = (&controllers.EIPAssociationReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("EIPAssociation"),
 	}).SetupWithManager(mgr)
 	if err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "EIP")
+		setupLog.Error(err, "unable to create controller", "controller", "EIPAssociation")
 		os.Exit(1)
 	}
-	err = (&controllers.ENIReconciler{
-		Client:           cachingClient,
+
+	err = (&controllers.EIPAssociationSetReconciler{
+		Client:           mgr.GetClient(),
 		NonCachingClient: nonCachingClient,
-		Log:              ctrl.Log.WithName("controllers").WithName("ENI"),
-		EC2:              ec2,
-		Tags:             defaultTagsMap,
+		Log:              ctrl.Log.WithName("controllers").WithName("EIPAssociationSet"),
 	}).SetupWithManager(mgr)
 	if err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "ENI")
+		setupLog.Error(err, "unable to create controller", "controller", "EIPAssociationSet")
 		os.Exit(1)
 	}
-	err = (&controllers.EIPAssociationReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("EIPAssociation"),
+
+	err = (&controllers.EIPInventoryReconciler{
+		Client:           mgr.GetClient(),
+		NonCachingClient: nonCachingClient,
+		Log:              ctrl.Log.WithName("controllers").WithName("EIPInventory"),
 	}).SetupWithManager(mgr)
 	if err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "EIPAssociation")
+		setupLog.Error(err, "unable to create controller", "controller", "EIPInventory")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
 
+	if err := mgr.AddMetricsExtraHandler(controllers.EIPInventoryPath, &controllers.EIPInventoryHandler{Client: cachingClient}); err != nil {
+		setupLog.Error(err, "unable to serve EIP inventories")
+		os.Exit(1)
+	}
+
+	if webhookPort != 0 {
+		mgr.GetWebhookServer().Register(controllers.PolicyWebhookPath, &webhook.Admission{
+			Handler: &controllers.PolicyValidator{Client: cachingClient},
+		})
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -145,3 +278,18 @@ func parseTags(tagMap *map[string]string, tags string) {
 		}
 	}
 }
+
+// skipController returns whether a controller is not to be started because
+// the preflight check found EC2 permissions it needs to be missing.
+func skipController(preflight *controllers.Preflight, requirePermissions bool, name, permissions string) bool {
+	if preflight == nil {
+		return false
+	}
+	missing := preflight.MissingPermissions(permissions)
+	if !requirePermissions || len(missing) == 0 {
+		preflight.AddController(permissions)
+		return false
+	}
+	setupLog.Info("not starting controller because of missing permissions", "controller", name, "missing", missing)
+	return true
+}